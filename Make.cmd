@@ -1,4 +1,5 @@
 echo off;
 echo "Limitless.Badger Windows Compile Script - temporarily changes GOPATH, it is reverted once the script ends."
 SET GOPATH=%cd%\vendor
+SET GO111MODULE=off
 go build -o bin/badger.exe src/main.go
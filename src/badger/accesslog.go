@@ -0,0 +1,126 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// RequestIDHeader carries the request ID to and from Badger
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID matches request IDs accepted from upstream proxies
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// metricMethods are the HTTP methods used as metric labels, any other
+// method is counted as 'other' to keep the label values bounded
+var metricMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPost:    true,
+	http.MethodPut:     true,
+	http.MethodPatch:   true,
+	http.MethodDelete:  true,
+	http.MethodConnect: true,
+	http.MethodOptions: true,
+	http.MethodTrace:   true,
+}
+
+// statusRecorder captures the status code and size of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	written    int64
+}
+
+// WriteHeader implements http.ResponseWriter
+func (recorder *statusRecorder) WriteHeader(statusCode int) {
+	if recorder.statusCode == 0 {
+		recorder.statusCode = statusCode
+	}
+	recorder.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write implements http.ResponseWriter
+func (recorder *statusRecorder) Write(p []byte) (int, error) {
+	if recorder.statusCode == 0 {
+		recorder.statusCode = http.StatusOK
+	}
+	n, err := recorder.ResponseWriter.Write(p)
+	recorder.written += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher when the wrapped writer does
+func (recorder *statusRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// requestID returns the request ID set by an upstream proxy, or a new one
+func requestID(r *http.Request) string {
+	if id := r.Header.Get(RequestIDHeader); validRequestID.MatchString(id) {
+		return id
+	}
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// methodLabel returns the metric label for an HTTP method
+func methodLabel(method string) string {
+	if metricMethods[method] {
+		return method
+	}
+	return "other"
+}
+
+// instrument wraps the handler for route to record its metrics and access
+// log. The project is only known once the router has matched the route.
+func (badger *Badger) instrument(route string, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := requestID(r)
+		w.Header().Set(RequestIDHeader, id)
+		recorder := &statusRecorder{ResponseWriter: w}
+		handler.ServeHTTP(recorder, r)
+		if recorder.statusCode == 0 {
+			recorder.statusCode = http.StatusOK
+		}
+		duration := time.Since(start)
+
+		badger.Metrics.observe(metricHTTPRequestDuration, duration, route)
+		badger.Metrics.add(metricHTTPRequests, 1, route, methodLabel(r.Method), strconv.Itoa(recorder.statusCode))
+		if badger.accessLog == nil {
+			return
+		}
+		badger.accessLog.Info("method=%s path=%s project=%s status=%d bytes=%d duration=%s request_id=%s remote=%s",
+			r.Method,
+			strconv.Quote(r.URL.EscapedPath()),
+			mux.Vars(r)["project"],
+			recorder.statusCode,
+			recorder.written,
+			duration,
+			id,
+			r.RemoteAddr)
+	})
+}
@@ -0,0 +1,112 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+)
+
+// embedded holds the default pages and badge images so that the binary
+// runs without the pages and badges directories
+//
+//go:embed assets/pages/*.html assets/pages/layouts assets/pages/partials assets/pages/css assets/pages/js assets/badges
+var embedded embed.FS
+
+var (
+	// embeddedPages are the default page templates, CSS and JS files
+	embeddedPages = mustSub(embedded, "assets/pages")
+	// embeddedBadges are the default badge images
+	embeddedBadges = mustSub(embedded, "assets/badges")
+)
+
+// mustSub returns the subtree of fsys at dir
+func mustSub(fsys fs.FS, dir string) fs.FS {
+	sub, err := fs.Sub(fsys, dir)
+	if err != nil {
+		panic(err)
+	}
+	return sub
+}
+
+// overlayFS serves the files in a directory on disk and falls back to
+// another file system for the files that don't exist in the directory
+type overlayFS struct {
+	// dir is blank to only serve the fallback
+	dir      string
+	fallback fs.FS
+}
+
+// Open opens name from the directory, or from the fallback if the
+// directory doesn't have it
+func (overlay overlayFS) Open(name string) (fs.File, error) {
+	if overlay.dir != "" {
+		file, err := os.DirFS(overlay.dir).Open(name)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return overlay.fallback.Open(name)
+}
+
+// ReadDir lists the files of a directory in both the directory on disk and
+// the fallback, the ones on disk replace the ones with the same name
+func (overlay overlayFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	entries, err := fs.ReadDir(overlay.fallback, name)
+	if overlay.dir == "" {
+		return entries, err
+	}
+	dirEntries, dirErr := fs.ReadDir(os.DirFS(overlay.dir), name)
+	if err != nil && dirErr != nil {
+		return nil, err
+	}
+	merged := make(map[string]fs.DirEntry, len(entries)+len(dirEntries))
+	for _, entry := range entries {
+		merged[entry.Name()] = entry
+	}
+	for _, entry := range dirEntries {
+		merged[entry.Name()] = entry
+	}
+	entries = make([]fs.DirEntry, 0, len(merged))
+	for _, entry := range merged {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries, nil
+}
+
+// pages returns the page templates and assets, the files in PagesPath
+// override the embedded ones
+func (badger *Badger) pages() fs.FS {
+	return overlayFS{dir: badger.PagesPath, fallback: embeddedPages}
+}
+
+// badges returns the badge images, the files in BadgesPath override the
+// embedded ones
+func (badger *Badger) badges() fs.FS {
+	return badgesFS(badger.BadgesPath)
+}
+
+// badgesFS returns the badge images in badgesPath over the embedded ones
+func badgesFS(badgesPath string) fs.FS {
+	return overlayFS{dir: badgesPath, fallback: embeddedBadges}
+}
@@ -0,0 +1,77 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	badger "."
+)
+
+func TestEmbeddedAssets(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeProjectFile(t, dir, "sample.bbproj", validProjectJSON)
+	pagesDir := filepath.Join(dir, "pages")
+	os.MkdirAll(filepath.Join(pagesDir, "css"), 0755)
+	writeProjectFile(t, filepath.Join(pagesDir, "css"), "page.css", "body{color:red}")
+
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: 8000},
+		ProjectsPath: dir,
+		PagesPath:    pagesDir,
+		BadgesPath:   filepath.Join(dir, "badges"),
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		contains string
+	}{
+		{"Override", "/css/page.css", "body{color:red}"},
+		{"EmbeddedAsset", "/css/normalize.css", "normalize.css"},
+		{"EmbeddedTemplate", "/", `href="/css/page.css"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			badgerBadger.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", test.path, nil))
+			if recorder.Code != http.StatusOK {
+				t.Errorf("Code should be %d and not %d", http.StatusOK, recorder.Code)
+			}
+			if !strings.Contains(recorder.Body.String(), test.contains) {
+				t.Errorf("Body should contain '%s'", test.contains)
+			}
+		})
+	}
+
+	t.Run("EmbeddedBadges", func(t *testing.T) {
+		validationErrors := badger.ValidateProjectAssets(badgerBadger.Projects()["sample"], badgerBadger.BadgesPath)
+		if len(validationErrors) > 0 {
+			t.Errorf("The embedded badges should be found: %v", validationErrors)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+const (
+	// BadgeLayoutAbsolute places the overlays at their positions
+	BadgeLayoutAbsolute = "absolute"
+	// BadgeLayoutHorizontal stacks the overlays left to right
+	BadgeLayoutHorizontal = "horizontal"
+	// BadgeLayoutVertical stacks the overlays top to bottom
+	BadgeLayoutVertical = "vertical"
+)
+
+const (
+	// BadgeAlignLeft anchors the stacked overlays to the left edge
+	BadgeAlignLeft = "left"
+	// BadgeAlignCenter centers the stacked overlays
+	BadgeAlignCenter = "center"
+	// BadgeAlignRight anchors the stacked overlays to the right edge
+	BadgeAlignRight = "right"
+)
+
+// defaultLabelColor is the colour of the overlay labels
+const defaultLabelColor = "#333"
+
+// badgeLayout returns the layout of a badge in lowercase
+func badgeLayout(badgeConfig BadgeConfig) string {
+	if badgeConfig.Layout == "" {
+		return BadgeLayoutAbsolute
+	}
+	return strings.ToLower(badgeConfig.Layout)
+}
+
+// badgeAlign returns the alignment of a badge in lowercase
+func badgeAlign(badgeConfig BadgeConfig) string {
+	if badgeConfig.Align == "" {
+		return BadgeAlignLeft
+	}
+	return strings.ToLower(badgeConfig.Align)
+}
+
+// overlaySize returns the size of an overlay with its label for a status
+// badge of badgeSize
+func overlaySize(overlay BadgeOverlay, badgeSize image.Point) image.Point {
+	label := labelSize(overlay.Label)
+	if label.X == 0 {
+		return badgeSize
+	}
+	size := image.Point{X: label.X + labelGap + badgeSize.X, Y: badgeSize.Y}
+	if label.Y > size.Y {
+		size.Y = label.Y
+	}
+	return size
+}
+
+// layoutOverlays places overlays of sizes on a badge. It returns the top
+// left of every overlay and the size of the canvas, which is the size of
+// the background unless the badge is sized to fit the overlays.
+func layoutOverlays(badgeConfig BadgeConfig, overlays []BadgeOverlay, sizes []image.Point, background image.Point) ([]image.Point, image.Point) {
+	positions := make([]image.Point, len(overlays))
+	padding := image.Point{X: badgeConfig.Padding, Y: badgeConfig.Padding}
+	layout := badgeLayout(badgeConfig)
+
+	if layout == BadgeLayoutAbsolute {
+		var content image.Point
+		for i, overlay := range overlays {
+			positions[i] = image.Point{X: overlay.Position.Left, Y: overlay.Position.Top}
+			content.X = maxInt(content.X, positions[i].X+sizes[i].X)
+			content.Y = maxInt(content.Y, positions[i].Y+sizes[i].Y)
+		}
+		if !badgeConfig.AutoSize {
+			return positions, background
+		}
+		// the positions are inside the padding on every edge
+		for i := range positions {
+			positions[i] = positions[i].Add(padding)
+		}
+		return positions, fitCanvas(content, padding, background)
+	}
+
+	// the size of the stack of overlays
+	var content image.Point
+	for i := range overlays {
+		if layout == BadgeLayoutHorizontal {
+			content.X += sizes[i].X
+			content.Y = maxInt(content.Y, sizes[i].Y)
+		} else {
+			content.X = maxInt(content.X, sizes[i].X)
+			content.Y += sizes[i].Y
+		}
+	}
+	if len(overlays) > 1 {
+		gaps := badgeConfig.Spacing * (len(overlays) - 1)
+		if layout == BadgeLayoutHorizontal {
+			content.X += gaps
+		} else {
+			content.Y += gaps
+		}
+	}
+
+	canvas := background
+	if badgeConfig.AutoSize {
+		canvas = fitCanvas(content, padding, background)
+	}
+	align := badgeAlign(badgeConfig)
+	origin := image.Point{
+		X: alignOffset(align, canvas.X-2*badgeConfig.Padding, content.X) + badgeConfig.Padding,
+		Y: (canvas.Y - content.Y) / 2,
+	}
+	next := origin
+	for i := range overlays {
+		if layout == BadgeLayoutHorizontal {
+			positions[i] = image.Point{X: next.X, Y: origin.Y + (content.Y-sizes[i].Y)/2}
+			next.X += sizes[i].X + badgeConfig.Spacing
+		} else {
+			positions[i] = image.Point{X: origin.X + alignOffset(align, content.X, sizes[i].X), Y: next.Y}
+			next.Y += sizes[i].Y + badgeConfig.Spacing
+		}
+	}
+	return positions, canvas
+}
+
+// fitCanvas returns the size of a canvas that fits content with padding on
+// every edge. Without content, ie. when no overlay has a status, it is the
+// size of the background, but at least 1x1 as an empty image can't be
+// encoded.
+func fitCanvas(content image.Point, padding image.Point, background image.Point) image.Point {
+	canvas := content.Add(padding).Add(padding)
+	if content.X == 0 || content.Y == 0 {
+		canvas = background
+	}
+	return image.Point{X: maxInt(canvas.X, 1), Y: maxInt(canvas.Y, 1)}
+}
+
+// alignOffset returns the offset of an item of size in space
+func alignOffset(align string, space int, size int) int {
+	switch align {
+	case BadgeAlignCenter:
+		return (space - size) / 2
+	case BadgeAlignRight:
+		return space - size
+	default:
+		return 0
+	}
+}
+
+// maxInt returns the larger of two ints
+func maxInt(first int, second int) int {
+	if first > second {
+		return first
+	}
+	return second
+}
+
+// parseColor converts a colour name or hex colour to RGBA, black if it
+// isn't valid
+func parseColor(name string) color.RGBA {
+	if hex, ok := badgeColors[strings.ToLower(name)]; ok {
+		name = hex
+	}
+	if !hexColorPattern.MatchString(name) {
+		return color.RGBA{A: 0xff}
+	}
+	hex := name[1:]
+	if len(hex) == 3 {
+		hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+	}
+	value, _ := strconv.ParseUint(hex, 16, 32)
+	return color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xff}
+}
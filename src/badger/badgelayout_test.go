@@ -0,0 +1,236 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	badger "."
+)
+
+// hasBadgeAt checks if an image has the pixels of a badge image at a point
+func hasBadgeAt(t *testing.T, img image.Image, at image.Point, badgeName string) bool {
+	expectedFile, err := os.Open(filepath.Join("assets", "badges", badgeName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer expectedFile.Close()
+	expected, _, err := image.Decode(expectedFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bounds := expected.Bounds()
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			_, _, _, a1 := expected.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			if a1 != 0xffff {
+				// blended with the background
+				continue
+			}
+			r1, g1, b1, _ := expected.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			r2, g2, b2, _ := img.At(at.X+x, at.Y+y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestBadgeLayout(t *testing.T) {
+	appVeyor := newStatusServer(t, func(r *http.Request) {})
+	defer appVeyor.Close()
+	travis := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"result": 1, "branch": "master", "message": "Break the build"}]`))
+	}))
+	defer travis.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	statuses := `"Statuses": [
+        {"Provider": "AppVeyor", "Url": "` + appVeyor.URL + `"},
+        {"Provider": "TravisCI", "Url": "` + travis.URL + `"}
+    ]`
+	templates := `"Template": {
+            "Background": "default.png",
+            "Badges": {"Passing": "build-passing.png", "Failing": "build-failing.png", "Unknown": "build-unknown.png"}
+        }`
+	writeProjectFile(t, dir, "horizontal.bbproj", `{
+    "Name": "Horizontal",
+    `+statuses+`,
+    "Badge": {
+        `+templates+`,
+        "Layout": "Horizontal",
+        "Spacing": 10,
+        "Padding": 4,
+        "AutoSize": true,
+        "LabelColor": "red",
+        "Overlays": [{"Provider": "AppVeyor", "Label": "CI"}, {"Provider": "TravisCI"}]
+    }
+}`)
+	writeProjectFile(t, dir, "vertical.bbproj", `{
+    "Name": "Vertical",
+    `+statuses+`,
+    "Badge": {
+        `+templates+`,
+        "Layout": "Vertical",
+        "Align": "Right",
+        "Spacing": 4,
+        "Overlays": [{"Provider": "AppVeyor"}, {"Provider": "TravisCI"}]
+    }
+}`)
+	writeProjectFile(t, dir, "absolute.bbproj", `{
+    "Name": "Absolute",
+    `+statuses+`,
+    "Badge": {
+        `+templates+`,
+        "Padding": 5,
+        "AutoSize": true,
+        "Overlays": [{"Provider": "AppVeyor", "Position": {"Left": 10, "Top": 2}}]
+    }
+}`)
+	// the only overlay has no build status to show
+	writeProjectFile(t, dir, "empty.bbproj", `{
+    "Name": "Empty",
+    "Statuses": [{"Type": "Tests", "Provider": "AppVeyor", "Url": "`+appVeyor.URL+`"}],
+    "Badge": {
+        "Template": {
+            "Badges": {"Passing": "build-passing.png", "Failing": "build-failing.png", "Unknown": "build-unknown.png"}
+        },
+        "AutoSize": true,
+        "Overlays": [{"Provider": "AppVeyor"}]
+    }
+}`)
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: 8000},
+		Fetch:        badger.FetchConfig{Retries: -1},
+		ProjectsPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+	badge := func(path string) image.Image {
+		recorder := httptest.NewRecorder()
+		badgerBadger.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Code should be %d and not %d", http.StatusOK, recorder.Code)
+		}
+		img, err := png.Decode(bytes.NewReader(recorder.Body.Bytes()))
+		if err != nil {
+			t.Fatalf("Badge should be a PNG image: %s", err.Error())
+		}
+		return img
+	}
+
+	t.Run("Horizontal", func(t *testing.T) {
+		img := badge("/horizontal/badge")
+		// padding, 'CI' label, gap, badge, spacing, badge, padding
+		expected := image.Point{X: 4 + 22 + 6 + 88 + 10 + 80 + 4, Y: 4 + 20 + 4}
+		if img.Bounds().Size() != expected {
+			t.Fatalf("Size should be '%s' and not '%s'", expected, img.Bounds().Size())
+		}
+		if !hasBadgeAt(t, img, image.Point{X: 32, Y: 4}, "build-passing.png") {
+			t.Errorf("Passing badge should follow the label")
+		}
+		if !hasBadgeAt(t, img, image.Point{X: 130, Y: 4}, "build-failing.png") {
+			t.Errorf("Failing badge should follow the spacing")
+		}
+		// the top of the 'C' is centered next to the badge
+		r, g, b, _ := img.At(6, 7).RGBA()
+		if r>>8 != 0xe0 || g>>8 != 0x5d || b>>8 != 0x44 {
+			t.Errorf("Label should be drawn in the label colour")
+		}
+	})
+
+	t.Run("VerticalRight", func(t *testing.T) {
+		img := badge("/vertical/badge")
+		if img.Bounds().Size() != (image.Point{X: 400, Y: 50}) {
+			t.Fatalf("Size should be the background size and not '%s'", img.Bounds().Size())
+		}
+		if !hasBadgeAt(t, img, image.Point{X: 312, Y: 3}, "build-passing.png") {
+			t.Errorf("Passing badge should be anchored to the right")
+		}
+		if !hasBadgeAt(t, img, image.Point{X: 320, Y: 27}, "build-failing.png") {
+			t.Errorf("Failing badge should be stacked below")
+		}
+	})
+
+	t.Run("AbsolutePadding", func(t *testing.T) {
+		img := badge("/absolute/badge")
+		// padding, position, badge, padding
+		expected := image.Point{X: 5 + 10 + 88 + 5, Y: 5 + 2 + 20 + 5}
+		if img.Bounds().Size() != expected {
+			t.Fatalf("Size should be '%s' and not '%s'", expected, img.Bounds().Size())
+		}
+		if !hasBadgeAt(t, img, image.Point{X: 15, Y: 7}, "build-passing.png") {
+			t.Errorf("Passing badge should be placed inside the padding")
+		}
+	})
+
+	t.Run("NoOverlays", func(t *testing.T) {
+		img := badge("/empty/badge")
+		if img.Bounds().Size() != (image.Point{X: 1, Y: 1}) {
+			t.Errorf("Size should be '1x1' and not '%s'", img.Bounds().Size())
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		validationErrors := badger.ValidateProject(badger.ProjectConfig{
+			Name:     "Sample",
+			Statuses: []badger.StatusConfig{{Provider: "AppVeyor", URL: "https://ci.appveyor.com"}},
+			Badge: badger.BadgeConfig{
+				Template: badger.BadgeTemplateConfig{Badges: badger.BadgeTemplates{Passing: "p.png", Failing: "f.png", Unknown: "u.png"}},
+				Layout:   "Diagonal",
+				Align:    "Top",
+				Overlays: []badger.BadgeOverlay{{Provider: "AppVeyor", Label: "Build ✓"}},
+			},
+		})
+		expected := []string{"Badge.Layout", "Badge.Align", "Badge.Overlays[0].Label"}
+		if len(validationErrors) != len(expected) {
+			t.Fatalf("Validation errors should be %v and not %v", expected, validationErrors)
+		}
+		for i, field := range expected {
+			if validationErrors[i].Field != field {
+				t.Errorf("Field should be '%s' and not '%s'", field, validationErrors[i].Field)
+			}
+		}
+	})
+
+	t.Run("ValidateFit", func(t *testing.T) {
+		path := writeProjectFile(t, dir, "wide.bbproj", `{
+    "Name": "Wide",
+    `+statuses+`,
+    "Badge": {
+        `+templates+`,
+        "Layout": "Horizontal",
+        "Spacing": 120,
+        "Overlays": [{"Provider": "AppVeyor"}, {"Provider": "TravisCI"}, {"Provider": "AppVeyor", "Label": "Again"}]
+    }
+}`)
+		validationErrors := badger.ValidateProjectFiles([]string{path}, "")
+		if len(validationErrors) != 1 || validationErrors[0].Field != "Badge.Layout" {
+			t.Errorf("Stacked overlays should not fit on the background, got %v", validationErrors)
+		}
+	})
+}
@@ -16,18 +16,17 @@ package badger
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
-	"html/template"
 	"image"
-	"io"
-	"io/ioutil"
+	"io/fs"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"image/draw"
@@ -35,28 +34,61 @@ import (
 	png "image/png"
 
 	"./parsers"
-	"github.com/donovansolms/lumberjack"
 	"github.com/gorilla/mux"
 	logging "github.com/op/go-logging"
-	"github.com/tylerb/graceful"
 )
 
+// Badger is the badge and status page server
 type Badger struct {
-	log         *logging.Logger
+	log *logging.Logger
+	// accessLog is nil when the access log is disabled
+	accessLog   *logging.Logger
 	router      *mux.Router
 	bindAddress string
-	Projects    map[string]ProjectConfig
-	PagesPath   string
-	BadgesPath  string
-	cacheSince  string
-	cacheUntil  string
+	serverIP    string
+	serverPort  int
+	// tlsConfig and certificates are nil unless TLS is enabled
+	tlsConfig       *tls.Config
+	certificates    *certificateLoader
+	redirectAddress string
+	// lifecycleLock guards servers and stopPolling while starting and
+	// shutting down
+	lifecycleLock sync.Mutex
+	servers       []*http.Server
+	stopPolling   context.CancelFunc
+	polling       sync.WaitGroup
+	adminToken    string
+	// sites are the virtual hosts followed by the default site
+	sites []*site
+	// projectsLock guards the projects of the sites during reloads
+	projectsLock sync.RWMutex
+	// templatesErr is the last template parse error, guarded by projectsLock
+	templatesErr error
+	devMode      bool
+	// PagesPath and BadgesPath are directories with files that override
+	// the embedded pages and badge images
+	PagesPath  string
+	BadgesPath string
+	// Fetcher fetches the provider statuses, it can be replaced before
+	// starting the server, ie. to use a different transport
+	Fetcher *Fetcher
+	// Metrics is served on the metrics path, nil when metrics are disabled
+	Metrics         *Metrics
+	statuses        *statusCache
+	events          *eventHub
+	refreshInterval time.Duration
+	cacheSince      string
+	cacheUntil      string
 }
 
 // New creates a new instance of Badger
-func New(config Config) (Badger, error) {
+func New(config Config) (*Badger, error) {
 
 	// set up logging
-	log := NewLog(config.Log)
+	log, err := NewLog(config.Log)
+	if err != nil {
+		return nil, errors.New("Unable to set up logging: " + err.Error())
+	}
 
 	log.Debug("Setting up Badger...")
 	var projectsPath string
@@ -66,82 +98,121 @@ func New(config Config) (Badger, error) {
 		var err error
 		projectsPath, err = filepath.Abs(config.ProjectsPath)
 		if err != nil {
-			return Badger{}, errors.New("Unable to get project path: " + err.Error())
+			return nil, errors.New("Unable to get project path: " + err.Error())
 		}
 	}
 
 	if config.Server.IP == "" {
-		return Badger{}, errors.New("You must specify a bind address")
+		return nil, errors.New("You must specify a bind address")
 	}
 	if config.Server.Port == 0 {
-		return Badger{}, errors.New("You must specify a bind port")
+		return nil, errors.New("You must specify a bind port")
 	}
 
-	// Get all the project configs
-	files, err := ioutil.ReadDir(projectsPath)
+	fetcher, err := NewFetcher(config.Fetch)
 	if err != nil {
-		return Badger{}, errors.New("Unable to open project path '" + projectsPath + "'")
+		return nil, errors.New("Unable to set up fetching: " + err.Error())
 	}
-	badger := Badger{
+
+	badger := &Badger{
 		log:         log,
 		bindAddress: fmt.Sprintf("%s:%d", config.Server.IP, config.Server.Port),
-		Projects:    make(map[string]ProjectConfig),
-		PagesPath:   "pages",
-		BadgesPath:  "badges",
+		serverIP:    config.Server.IP,
+		serverPort:  config.Server.Port,
+		adminToken:  config.Server.AdminToken,
+		devMode:     config.DevMode,
+		PagesPath:   config.PagesPath,
+		BadgesPath:  config.BadgesPath,
+		Fetcher:     fetcher,
+		statuses:    newStatusCache(),
+		events:      newEventHub(),
+	}
+	badger.sites, err = newSites(config, projectsPath)
+	if err != nil {
+		return nil, errors.New("Unable to set up virtual hosts: " + err.Error())
+	}
+	if badger.PagesPath == "" {
+		badger.PagesPath = "pages"
+	}
+	if badger.BadgesPath == "" {
+		badger.BadgesPath = "badges"
+	}
+	badger.refreshInterval = time.Duration(config.Fetch.RefreshSeconds) * time.Second
+	if badger.refreshInterval <= 0 {
+		badger.refreshInterval = defaultRefreshInterval
+	}
+	badger.tlsConfig, badger.certificates, err = newServerTLSConfig(config.Server.TLS)
+	if err != nil {
+		return nil, errors.New("Unable to set up TLS: " + err.Error())
+	}
+	if badger.tlsConfig != nil && config.Server.TLS.RedirectPort != 0 {
+		badger.redirectAddress = fmt.Sprintf("%s:%d", config.Server.IP, config.Server.TLS.RedirectPort)
+	}
+	if config.Log.AccessLog {
+		badger.accessLog = logging.MustGetLogger(accessLogModule)
+	}
+	if !config.Metrics.Disabled {
+		badger.Metrics = NewMetrics()
+		fetcher.Metrics = badger.Metrics
 	}
 
-	basePath := config.Server.BasePath
+	basePath := strings.TrimSuffix(config.Server.BasePath, "/")
 
+	// Every route is logged and measured under its path template
 	router := mux.NewRouter()
-	router.HandleFunc(basePath+"/", badger.RootHandler)
-	router.HandleFunc(basePath+"/{project}", badger.ProjectPageHandler)
-	router.HandleFunc(basePath+"/{project}/badge", badger.ProjectBadgeHandler)
-	router.HandleFunc(basePath+"/{project}/status", badger.ProjectStatusHandler)
-	// serve CSS files directly
-	cssServer := http.StripPrefix(basePath+"/css/", http.FileServer(http.Dir("./pages/css/")))
-	router.PathPrefix(basePath + "/css/").Handler(cssServer)
-	// serve JS files directly
-	jsServer := http.StripPrefix(basePath+"/js/", http.FileServer(http.Dir("./pages/js/")))
-	router.PathPrefix(basePath + "/js/").Handler(jsServer)
-	// serve image files directly
-	imgServer := http.StripPrefix(basePath+"/i/", http.FileServer(http.Dir("./pages/i/")))
-	router.PathPrefix(basePath + "/i/").Handler(imgServer)
+	handle := func(path string, handler http.HandlerFunc) *mux.Route {
+		return router.Handle(basePath+path, badger.instrument(path, handler))
+	}
+	handle("/healthz", badger.HealthzHandler).Methods("GET")
+	handle("/readyz", badger.ReadyzHandler).Methods("GET")
+	if badger.adminToken != "" {
+		handle("/admin/reload", badger.ReloadHandler).Methods("POST")
+	}
+	if badger.Metrics != nil {
+		metricsPath := config.Metrics.Path
+		if metricsPath == "" {
+			metricsPath = defaultMetricsPath
+		}
+		handle(metricsPath, badger.MetricsHandler).Methods("GET")
+	}
+	badger.routeSites(router)
+	// unmatched paths are counted together
+	router.NotFoundHandler = badger.instrument("unmatched", http.NotFoundHandler())
 
 	badger.router = router
+	badger.loadTemplates()
 
-	// Parse all the project files
-	log.Debug("Loading project files...")
-	for _, file := range files {
-		if file.IsDir() {
-			continue
+	// Parse all the project files of every site
+	for _, site := range badger.sites {
+		log.Debug("Loading project files for the %s site...", site.label())
+		loaded, failed, err := loadProjectFiles(site.projectsPath)
+		if err != nil {
+			return nil, err
 		}
-		if filepath.Ext(file.Name()) != ".bbproj" {
-			continue
+		for _, reason := range failed {
+			log.Warning(reason)
 		}
-
-		fileBytes, err := ioutil.ReadFile(filepath.Join(projectsPath, file.Name()))
-		if err != nil {
-			log.Warning("Unable to read project file '%s': %s", filepath.Join(projectsPath, file.Name()), err.Error())
-			continue
+		projects, aliases, conflicts := indexProjects(loaded)
+		for fileName, reason := range conflicts {
+			log.Warning(reason)
+			delete(loaded, fileName)
 		}
-		var projectConfig ProjectConfig
-		err = json.Unmarshal(fileBytes, &projectConfig)
-		if err != nil {
-			log.Warning("Unable to parse project file '%s': %s", file.Name(), err.Error())
-			continue
+		for _, projectConfig := range projects {
+			badger.warnProjectAssets(projectConfig)
+			log.Debug("Project '%s' loaded", projectConfig.Name)
+		}
+		site.projects = projects
+		site.aliases = aliases
+		site.projectFiles = loaded
+		// Proper english for config vs configs count
+		if len(site.projects) == 0 {
+			log.Error("No project configs loaded for the %s site", site.label())
+			return nil, errors.New("No project configs loaded")
+		} else if len(site.projects) == 1 {
+			log.Info("Loaded %d project config for the %s site", len(site.projects), site.label())
+		} else {
+			log.Info("Loaded %d project configs for the %s site", len(site.projects), site.label())
 		}
-
-		badger.Projects[strings.Replace(strings.ToLower(projectConfig.Name), " ", "-", -1)] = projectConfig
-		log.Debug("Project '%s' loaded", projectConfig.Name)
-	}
-	// Proper english for config vs configs count
-	if len(badger.Projects) == 0 {
-		log.Error("No project configs loaded")
-		return Badger{}, errors.New("No project configs loaded")
-	} else if len(badger.Projects) == 1 {
-		log.Info("Loaded %d project config", len(badger.Projects))
-	} else {
-		log.Info("Loaded %d project configs", len(badger.Projects))
 	}
 
 	badger.cacheSince = time.Now().Format(http.TimeFormat)
@@ -150,20 +221,14 @@ func New(config Config) (Badger, error) {
 	return badger, nil
 }
 
-// Start starts the HTPP server
-func (badger *Badger) Start() {
-	// https://github.com/golang/go/issues/4674 so I use graceful
-	// instead of http.ListenAndServe(badger.bindAddress, badger.router)
-	graceful.Run(badger.bindAddress, 10*time.Second, badger.router)
-}
-
-// RootHandler handles calls to the root path and renders
-// all the projects loaded on the root.html template
+// RootHandler handles calls to the root path and renders all the projects
+// loaded with their cached statuses on the root.html template. The query
+// can filter, sort and group the projects, ie. ?status=Failing&sort=name.
 func (badger *Badger) RootHandler(w http.ResponseWriter, r *http.Request) {
-	pagePath := filepath.Join(badger.PagesPath, "root.html")
-	badger.log.Debug("Loading root page at %s", pagePath)
+	route := badger.routeFor(r)
+	badger.log.Debug("Loading root page")
 
-	page, err := template.ParseFiles(pagePath)
+	page, err := badger.templatesFor(route.site).lookup("root.html")
 	if err != nil {
 		badger.log.Warning("Root page not found: %s.", err.Error())
 		w.WriteHeader(http.StatusInternalServerError)
@@ -171,9 +236,7 @@ func (badger *Badger) RootHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pageData := RootPageData{
-		Projects: badger.Projects,
-	}
+	pageData := badger.rootPageData(r, route)
 
 	err = page.Execute(w, pageData)
 	if err != nil {
@@ -182,23 +245,33 @@ func (badger *Badger) RootHandler(w http.ResponseWriter, r *http.Request) {
 
 }
 
-// ProjectPageHandler handles calls to /{project}
+// ProjectPageHandler handles calls to /{project}, ?branch= shows the
+// statuses of a branch
 func (badger *Badger) ProjectPageHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	project := vars["project"]
 	project = strings.ToLower(project)
 	badger.log.Debug("Request received for project page '%s'", project)
 
-	if projectConfig, ok := badger.Projects[project]; ok {
+	route := badger.routeFor(r)
+	if projectConfig, ok := badger.siteProject(route.site, project); ok {
+		branch, ok := badger.requestBranch(w, r)
+		if !ok {
+			return
+		}
 
-		pagePath := filepath.Join(badger.PagesPath, project+".html")
-		badger.log.Debug("Loading project page at %s", pagePath)
+		templates := badger.templatesFor(route.site)
+		pageName := project + ".html"
+		if projectConfig.Page.Template != "" {
+			pageName = projectConfig.Page.Template
+		}
+		badger.log.Debug("Loading project page %s", pageName)
 
-		page, err := template.ParseFiles(pagePath)
+		page, err := templates.lookup(pageName)
 		if err != nil {
 			badger.log.Warning("Project page not found: %s. Using default.", err.Error())
 			// load the default page
-			page, err = template.ParseFiles(filepath.Join(badger.PagesPath, "default.html"))
+			page, err = templates.lookup("default.html")
 			if err != nil {
 				badger.log.Error("Default page does not exist at '%s': %s", "default.html", err.Error())
 				w.WriteHeader(http.StatusInternalServerError)
@@ -207,12 +280,16 @@ func (badger *Badger) ProjectPageHandler(w http.ResponseWriter, r *http.Request)
 			}
 		}
 
-		overallStatus, providerStatuses := FetchAllStatuses(projectConfig.Statuses)
+		overallStatus, providerStatuses := badger.fetchBranchStatuses(route.site, project, projectConfig, branch)
 
 		pageData := PageData{
+			BasePath:    route.basePath,
+			Project:     project,
 			ProjectName: projectConfig.Name,
+			Branch:      branch,
 			Overall:     overallStatus,
 			Providers:   providerStatuses,
+			Branches:    badger.trackedBranches(route.site, project, projectConfig),
 		}
 
 		err = page.Execute(w, pageData)
@@ -227,97 +304,130 @@ func (badger *Badger) ProjectPageHandler(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-// ProjectBadgeHandler handles calls to /{project}/badge
+// ProjectBadgeHandler handles calls to /{project}/badge and to
+// /{project}/badge/{variant} for the named badge variants, ?branch= shows
+// the statuses of a branch
 func (badger *Badger) ProjectBadgeHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	project := vars["project"]
 	project = strings.ToLower(project)
 	badger.log.Debug("Request received for project badge '%s'", project)
 
-	if projectConfig, ok := badger.Projects[project]; ok {
+	route := badger.routeFor(r)
+	if projectConfig, ok := badger.siteProject(route.site, project); ok {
+		start := time.Now()
+		branch, ok := badger.requestBranch(w, r)
+		if !ok {
+			return
+		}
 
-		// Check if this project has an overlay section
-		if len(projectConfig.Badge.Overlays) == 0 {
+		variant := vars["variant"]
+		badgeConfig, ok := projectBadge(projectConfig, variant)
+		if !ok {
+			badger.log.Error("Badge variant '%s' not found for project '%s'", variant, project)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(fmt.Sprintf("Badge variant '%s' not found for project '%s'", variant, project)))
+			return
+		}
+
+		// Check if this badge has an overlay section
+		if len(badgeConfig.Overlays) == 0 {
 			badger.log.Error("No overlays found for project '%s'", project)
 			w.WriteHeader(http.StatusNotImplemented)
 			w.Write([]byte(fmt.Sprintf("No overlays found for project '%s'", project)))
 			return
 		}
 
-		backgroundPath := filepath.Join(badger.BadgesPath, projectConfig.Badge.Template.Background)
-		badger.log.Debug("Building project badge from %s", backgroundPath)
-
-		reader, err := os.Open(backgroundPath)
-		if err != nil {
-			badger.log.Warning("Project badge not found: %s. Using default.", err.Error())
-			// load the default badge background
-			reader, err = os.Open(filepath.Join(badger.BadgesPath, "default.png"))
+		badges := badger.badges()
+		var backgroundImage image.Image
+		if badgeConfig.Template.Background != "" || !badgeConfig.AutoSize {
+			badger.log.Debug("Building project badge from %s", badgeConfig.Template.Background)
+			var err error
+			backgroundImage, err = decodeBadge(badges, badgeConfig.Template.Background)
 			if err != nil {
-				badger.log.Error("Default badge does not exist at '%s': %s", "default.png", err.Error())
-				w.WriteHeader(http.StatusInternalServerError)
-				w.Write([]byte(fmt.Sprintf("Default badge could not be loaded '%s': %s", "default.png", err.Error())))
-				return
+				badger.log.Warning("Project badge not found: %s. Using default.", err.Error())
+				// load the default badge background
+				backgroundImage, err = decodeBadge(badges, "default.png")
+				if err != nil {
+					badger.log.Error("Error loading background image: %s", err.Error())
+					w.WriteHeader(http.StatusInternalServerError)
+					w.Write([]byte(fmt.Sprintf("Error loading background image: %s", err.Error())))
+					return
+				}
 			}
 		}
-		backgroundImageRaw, imageType, err := image.Decode(reader)
-		if err != nil {
-			badger.log.Error("Error loading background image: %s", err.Error())
-			w.WriteHeader(http.StatusInternalServerError)
-			w.Write([]byte(fmt.Sprintf("Error loading background image: %s", err.Error())))
-			return
-		}
-		badger.log.Debug("Background image loaded as type '%s'", imageType)
-		bounds := backgroundImageRaw.Bounds()
-		backgroundImage := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
-		draw.Draw(backgroundImage, backgroundImage.Bounds(), backgroundImageRaw, bounds.Min, draw.Src)
 
-		overallStatus, providerStatuses := FetchAllStatuses(projectConfig.Statuses)
+		overallStatus, providerStatuses := badger.fetchBranchStatuses(route.site, project, projectConfig, branch)
 		_ = overallStatus
 
-		// map statuses to a map based on proper name
+		// map statuses to a map based on proper name, the overlays show
+		// the build statuses
 		providerStatusMap := make(map[string]parsers.ProviderResult)
 		for _, status := range providerStatuses {
+			if status.Type != StatusTypeBuild {
+				continue
+			}
 			badger.log.Debug("Mapping provider '%s'", status.ProperName)
 			providerStatusMap[status.Provider] = status
 		}
-		badger.log.Debug("Overlays available: %d", len(projectConfig.Badge.Overlays))
-		for _, overlay := range projectConfig.Badge.Overlays {
-			if status, ok := providerStatusMap[overlay.Provider]; ok {
-				badger.log.Debug("Overlaying provider '%s' status: %s", overlay.Provider, status.Status)
-				var imageReader io.Reader
-				switch status.Status {
-				case parsers.ProviderStatusSuccess:
-					imageReader, err = os.Open(filepath.Join(badger.BadgesPath, projectConfig.Badge.Template.Badges.Passing))
-				case parsers.ProviderStatusFailed:
-					imageReader, err = os.Open(filepath.Join(badger.BadgesPath, projectConfig.Badge.Template.Badges.Failing))
-				case parsers.ProviderStatusUnknown:
-					imageReader, err = os.Open(filepath.Join(badger.BadgesPath, projectConfig.Badge.Template.Badges.Unknown))
-				}
-				if err != nil {
-					badger.log.Error("Unable to load status badge: %s", err.Error())
-					continue
-				}
-
-				overlayImage, _, err := image.Decode(imageReader)
-				if err != nil {
-					badger.log.Error("Status badge error: %s", err.Error())
-					continue
-				}
-				topLeft := image.Point{
-					X: overlay.Position.Left,
-					Y: overlay.Position.Top,
-				}
-				badgePlacement := overlayImage.Bounds().Add(topLeft)
-				draw.Draw(backgroundImage, badgePlacement, overlayImage, image.ZP, draw.Over)
-			} else {
+		badger.log.Debug("Overlays available: %d", len(badgeConfig.Overlays))
+		overlays := make([]BadgeOverlay, 0, len(badgeConfig.Overlays))
+		overlayImages := make([]image.Image, 0, len(badgeConfig.Overlays))
+		sizes := make([]image.Point, 0, len(badgeConfig.Overlays))
+		for _, overlay := range badgeConfig.Overlays {
+			status, ok := providerStatusMap[overlay.Provider]
+			if !ok {
 				badger.log.Warning("Overlay provider '%s' not available in listed providers", overlay.Provider)
+				continue
+			}
+			badger.log.Debug("Overlaying provider '%s' status: %s", overlay.Provider, status.Status)
+			badgeName := badgeConfig.Template.Badges.Unknown
+			switch status.Status {
+			case parsers.ProviderStatusSuccess:
+				badgeName = badgeConfig.Template.Badges.Passing
+			case parsers.ProviderStatusFailed:
+				badgeName = badgeConfig.Template.Badges.Failing
+			}
+			overlayImage, err := decodeBadge(badges, badgeName)
+			if err != nil {
+				badger.log.Error("Unable to load status badge: %s", err.Error())
+				continue
+			}
+			overlays = append(overlays, overlay)
+			overlayImages = append(overlayImages, overlayImage)
+			sizes = append(sizes, overlaySize(overlay, overlayImage.Bounds().Size()))
+		}
+
+		var backgroundSize image.Point
+		if backgroundImage != nil {
+			backgroundSize = backgroundImage.Bounds().Size()
+		}
+		positions, canvasSize := layoutOverlays(badgeConfig, overlays, sizes, backgroundSize)
+		canvas := image.NewRGBA(image.Rectangle{Max: canvasSize})
+		if backgroundImage != nil {
+			draw.Draw(canvas, canvas.Bounds(), backgroundImage, backgroundImage.Bounds().Min, draw.Src)
+		}
+		labelColor := badgeConfig.LabelColor
+		if labelColor == "" {
+			labelColor = defaultLabelColor
+		}
+		for i, overlay := range overlays {
+			badgeAt := positions[i]
+			if label := labelSize(overlay.Label); label.X > 0 {
+				drawLabel(canvas, overlay.Label, positions[i].Add(image.Point{Y: (sizes[i].Y - label.Y) / 2}), parseColor(labelColor))
+				badgeAt.X += label.X + labelGap
 			}
+			badgeSize := overlayImages[i].Bounds().Size()
+			badgeAt.Y += (sizes[i].Y - badgeSize.Y) / 2
+			placement := image.Rectangle{Min: badgeAt, Max: badgeAt.Add(badgeSize)}
+			draw.Draw(canvas, placement, overlayImages[i], overlayImages[i].Bounds().Min, draw.Over)
 		}
-		img := image.Image(backgroundImage)
+		img := image.Image(canvas)
 		w.Header().Set("Cache-Control", "no-cache, private")
 		w.Header().Set("Last-Modified", badger.cacheSince)
 		w.Header().Set("Expires", badger.cacheUntil)
-		writeImage(badger.log, w, img)
+		writeBadge(badger.log, w, img, badgeFormat(badgeConfig))
+		badger.Metrics.observe(metricBadgeRenderDuration, time.Since(start), route.site.key(project))
 		badger.log.Info("Badge rendered")
 
 	} else {
@@ -336,18 +446,23 @@ func (badger *Badger) ProjectStatusHandler(w http.ResponseWriter, r *http.Reques
 	project = strings.ToLower(project)
 	badger.log.Debug("Request received for project status '%s'", project)
 
-	if projectConfig, ok := badger.Projects[project]; ok {
+	route := badger.routeFor(r)
+	if projectConfig, ok := badger.siteProject(route.site, project); ok {
+		branch, ok := badger.requestBranch(w, r)
+		if !ok {
+			return
+		}
 
-		overallStatus, providerStatuses := FetchAllStatuses(projectConfig.Statuses)
+		overallStatus, providerStatuses := badger.fetchBranchStatuses(route.site, project, projectConfig, branch)
 
-		pagePath := filepath.Join(badger.PagesPath, project+".ajax.html")
-		badger.log.Debug("Loading ajax project page at %s", pagePath)
+		templates := badger.templatesFor(route.site)
+		badger.log.Debug("Loading ajax project page %s.ajax.html", project)
 
-		page, err := template.ParseFiles(pagePath)
+		page, err := templates.lookup(project + ".ajax.html")
 		if err != nil {
 			badger.log.Warning("Ajax project page not found: %s. Using default.", err.Error())
 			// load the default page
-			page, err = template.ParseFiles(filepath.Join(badger.PagesPath, "ajax.html"))
+			page, err = templates.lookup("ajax.html")
 			if err != nil {
 				badger.log.Error("Default ajax page does not exist at '%s': %s", "ajax.html", err.Error())
 				w.WriteHeader(http.StatusInternalServerError)
@@ -357,7 +472,10 @@ func (badger *Badger) ProjectStatusHandler(w http.ResponseWriter, r *http.Reques
 		}
 
 		pageData := PageData{
+			BasePath:    route.basePath,
+			Project:     project,
 			ProjectName: projectConfig.Name,
+			Branch:      branch,
 			Overall:     overallStatus,
 			Providers:   providerStatuses,
 		}
@@ -375,6 +493,17 @@ func (badger *Badger) ProjectStatusHandler(w http.ResponseWriter, r *http.Reques
 	}
 }
 
+// decodeBadge opens and decodes a badge image
+func decodeBadge(badges fs.FS, name string) (image.Image, error) {
+	reader, err := badges.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	img, _, err := image.Decode(reader)
+	return img, err
+}
+
 // writeImage encodes an image 'img' in png format and writes it into ResponseWriter.
 func writeImage(log *logging.Logger, w http.ResponseWriter, img image.Image) {
 	buffer := new(bytes.Buffer)
@@ -389,58 +518,3 @@ func writeImage(log *logging.Logger, w http.ResponseWriter, img image.Image) {
 		log.Error("Unable to write image to the HTTP output: %s", err.Error())
 	}
 }
-
-// NewLog creates a new instance of the logger
-func NewLog(logConfig LogConfig) *logging.Logger {
-
-	// Collection of logging backends
-	var backendFormatters []logging.Backend
-	backendFormatters = make([]logging.Backend, 0)
-
-	// If Console logging is enabled
-	if logConfig.Console.Enabled == true {
-		var format = logging.MustStringFormatter(
-			logConfig.Console.Format,
-		)
-		backend := logging.NewLogBackend(os.Stderr, "", 0)
-		backendFormatter := logging.NewBackendFormatter(backend, format)
-		backendLeveled := logging.AddModuleLevel(backendFormatter)
-		backendLeveled.SetLevel(logging.DEBUG, "")
-		backendFormatters = append(backendFormatters, backendLeveled)
-	}
-	if logConfig.File.Enabled == true {
-		var format = logging.MustStringFormatter(
-			logConfig.File.Format,
-		)
-		logfile := &lumberjack.Logger{
-			Filename:   "logs/badger.log",
-			MaxSize:    int(logConfig.File.RotateSizeMB),
-			MaxBackups: 3,  // Number of files to keep
-			MaxAge:     30, // Days to keep
-		}
-		backend := logging.NewLogBackend(logfile, "", 0)
-		backendFormatter := logging.NewBackendFormatter(backend, format)
-		backendLeveled := logging.AddModuleLevel(backendFormatter)
-		backendLeveled.SetLevel(logging.DEBUG, "")
-		backendFormatters = append(backendFormatters, backendLeveled)
-	}
-
-	logging.SetBackend(backendFormatters...)
-
-	switch logConfig.Level {
-	case "DEBUG":
-		logging.SetLevel(logging.DEBUG, "")
-	case "INFO":
-		logging.SetLevel(logging.INFO, "")
-	case "WARNING":
-		logging.SetLevel(logging.WARNING, "")
-	case "ERROR":
-		logging.SetLevel(logging.ERROR, "")
-	case "CRITICAL":
-		logging.SetLevel(logging.CRITICAL, "")
-	}
-
-	log := logging.MustGetLogger("Badger")
-
-	return log
-}
@@ -0,0 +1,131 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	logging "github.com/op/go-logging"
+)
+
+const (
+	// BadgeFormatPNG encodes badges as PNG images, the default
+	BadgeFormatPNG = "png"
+	// BadgeFormatJPEG encodes badges as JPEG images on a white background
+	BadgeFormatJPEG = "jpeg"
+	// BadgeFormatGIF encodes badges as GIF images
+	BadgeFormatGIF = "gif"
+)
+
+// defaultVariant is the name of the Badge of a project at
+// /{project}/badge/{variant}
+const defaultVariant = "default"
+
+// variantPattern matches the names of the badge variants
+var variantPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// badgeFormats are the content types of the badge formats
+var badgeFormats = map[string]string{
+	BadgeFormatPNG:  "image/png",
+	BadgeFormatJPEG: "image/jpeg",
+	BadgeFormatGIF:  "image/gif",
+}
+
+// badgeFormat returns the output format of a badge in lowercase
+func badgeFormat(badgeConfig BadgeConfig) string {
+	format := strings.ToLower(badgeConfig.Format)
+	switch format {
+	case "":
+		return BadgeFormatPNG
+	case "jpg":
+		return BadgeFormatJPEG
+	}
+	return format
+}
+
+// projectBadge returns the badge variant of a project ignoring case, the
+// Badge for a blank variant or the default variant
+func projectBadge(projectConfig ProjectConfig, variant string) (BadgeConfig, bool) {
+	variant = strings.ToLower(variant)
+	if variant == "" || variant == defaultVariant {
+		return projectConfig.Badge, true
+	}
+	for name, badgeConfig := range projectConfig.Badges {
+		if strings.ToLower(name) == variant {
+			return badgeConfig, true
+		}
+	}
+	return BadgeConfig{}, false
+}
+
+// badgeVariants returns the names of the badge variants of a project sorted
+func badgeVariants(projectConfig ProjectConfig) []string {
+	variants := make([]string, 0, len(projectConfig.Badges))
+	for variant := range projectConfig.Badges {
+		variants = append(variants, variant)
+	}
+	sort.Strings(variants)
+	return variants
+}
+
+// encodeBadge encodes a badge image in a badge format
+func encodeBadge(img image.Image, format string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	var err error
+	switch format {
+	case BadgeFormatPNG:
+		err = png.Encode(buffer, img)
+	case BadgeFormatJPEG:
+		// JPEG has no transparency, transparent pixels would turn black
+		flattened := image.NewRGBA(img.Bounds())
+		draw.Draw(flattened, flattened.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		draw.Draw(flattened, flattened.Bounds(), img, img.Bounds().Min, draw.Over)
+		err = jpeg.Encode(buffer, flattened, &jpeg.Options{Quality: 90})
+	case BadgeFormatGIF:
+		err = gif.Encode(buffer, img, nil)
+	default:
+		err = errors.New("Unknown badge format '" + format + "'")
+	}
+	return buffer.Bytes(), err
+}
+
+// writeBadge encodes a badge image in a badge format and writes it into
+// the ResponseWriter
+func writeBadge(log *logging.Logger, w http.ResponseWriter, img image.Image, format string) {
+	encoded, err := encodeBadge(img, format)
+	if err != nil {
+		log.Error("Unable to encode badge: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Unable to encode badge: " + err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", badgeFormats[format])
+	w.Header().Set("Content-Length", strconv.Itoa(len(encoded)))
+	_, err = w.Write(encoded)
+	if err != nil {
+		log.Error("Unable to write badge to the HTTP output: %s", err.Error())
+	}
+}
@@ -0,0 +1,136 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	badger "."
+)
+
+func TestBadgeVariants(t *testing.T) {
+	provider := newStatusServer(t, func(r *http.Request) {})
+	defer provider.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	badges := `"Badges": {"Passing": "build-passing.png", "Failing": "build-failing.png", "Unknown": "build-unknown.png"}`
+	writeProjectFile(t, dir, "sample.bbproj", `{
+    "Name": "Sample",
+    "Statuses": [{"Provider": "AppVeyor", "Url": "`+provider.URL+`"}],
+    "Badge": {
+        "Template": {"Background": "default.png", `+badges+`},
+        "Overlays": [{"Provider": "AppVeyor", "Position": {"Left": 130, "Top": 15}}]
+    },
+    "Badges": {
+        "Banner": {
+            "Template": {`+badges+`},
+            "Layout": "Horizontal",
+            "AutoSize": true,
+            "Format": "JPEG",
+            "Overlays": [{"Provider": "AppVeyor", "Label": "AppVeyor"}]
+        },
+        "icon": {
+            "Template": {`+badges+`},
+            "AutoSize": true,
+            "Format": "gif",
+            "Overlays": [{"Provider": "AppVeyor"}]
+        }
+    }
+}`)
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: 8000},
+		Fetch:        badger.FetchConfig{Retries: -1},
+		ProjectsPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+	get := func(path string, code int) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		badgerBadger.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if recorder.Code != code {
+			t.Errorf("Code should be %d and not %d", code, recorder.Code)
+		}
+		return recorder
+	}
+	variants := []struct {
+		path        string
+		contentType string
+		decode      func(io.Reader) (image.Image, error)
+		size        image.Point
+	}{
+		{"/sample/badge", "image/png", png.Decode, image.Point{X: 400, Y: 50}},
+		{"/sample/badge/default", "image/png", png.Decode, image.Point{X: 400, Y: 50}},
+		// 'AppVeyor' label, gap and the passing badge
+		{"/sample/badge/banner", "image/jpeg", jpeg.Decode, image.Point{X: 94 + 6 + 88, Y: 20}},
+		{"/sample/badge/Icon", "image/gif", gif.Decode, image.Point{X: 88, Y: 20}},
+	}
+	for _, variant := range variants {
+		t.Run(variant.path, func(t *testing.T) {
+			recorder := get(variant.path, http.StatusOK)
+			if contentType := recorder.Header().Get("Content-Type"); contentType != variant.contentType {
+				t.Errorf("Content-Type should be '%s' and not '%s'", variant.contentType, contentType)
+			}
+			img, err := variant.decode(bytes.NewReader(recorder.Body.Bytes()))
+			if err != nil {
+				t.Fatalf("Unable to decode badge: %s", err.Error())
+			}
+			if img.Bounds().Size() != variant.size {
+				t.Errorf("Size should be '%s' and not '%s'", variant.size, img.Bounds().Size())
+			}
+		})
+	}
+
+	t.Run("UnknownVariant", func(t *testing.T) {
+		get("/sample/badge/readme", http.StatusNotFound)
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		statuses := []badger.StatusConfig{{Provider: "AppVeyor", URL: "https://ci.appveyor.com"}}
+		overlays := []badger.BadgeOverlay{{Provider: "AppVeyor"}}
+		template := badger.BadgeTemplateConfig{Badges: badger.BadgeTemplates{Passing: "p.png", Failing: "f.png", Unknown: "u.png"}}
+		validationErrors := badger.ValidateProject(badger.ProjectConfig{
+			Name:     "Sample",
+			Statuses: statuses,
+			Badges: map[string]badger.BadgeConfig{
+				"Default": {Template: template, Overlays: overlays},
+				"docs":    {Template: template, Overlays: overlays, Format: "bmp"},
+				"my.icon": {Template: template, Overlays: overlays},
+				"readme":  {},
+			},
+		})
+		expected := []string{"Badges.Default", "Badges.docs.Format", "Badges.my.icon", "Badges.readme.Overlays"}
+		if len(validationErrors) != len(expected) {
+			t.Fatalf("Validation errors should be %v and not %v", expected, validationErrors)
+		}
+		for i, field := range expected {
+			if validationErrors[i].Field != field {
+				t.Errorf("Field should be '%s' and not '%s'", field, validationErrors[i].Field)
+			}
+		}
+	})
+}
@@ -0,0 +1,137 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	// branchPlaceholder is replaced with the branch in the status URLs,
+	// ie. https://ci.appveyor.com/api/projects/owner/repo/branch/{branch}
+	branchPlaceholder = "{branch}"
+	// defaultBranchName replaces the placeholders for projects without a
+	// default branch
+	defaultBranchName = "master"
+	// branchSeparator separates the project and branch in the cache keys
+	branchSeparator = "@"
+	// maxBranchLength is the longest branch name accepted
+	maxBranchLength = 100
+)
+
+// branchListProviders return the builds of every branch, their parsers
+// can pick the build of a branch without a {branch} placeholder in the URL
+var branchListProviders = map[string]bool{
+	ProviderTravisCI: true,
+}
+
+// branchPattern matches the branch names that can be requested
+var branchPattern = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+
+// validBranch checks if a branch name can be requested, it is stricter
+// than git so that it is safe in the provider URLs
+func validBranch(branch string) bool {
+	return len(branch) <= maxBranchLength &&
+		branchPattern.MatchString(branch) &&
+		!strings.Contains(branch, "..") &&
+		!strings.Contains(branch, "//") &&
+		!strings.HasPrefix(branch, "-") &&
+		!strings.HasPrefix(branch, "/") &&
+		!strings.HasSuffix(branch, "/")
+}
+
+// branchStatuses returns the statuses of a project for a branch with the
+// {branch} placeholders in the URLs replaced by the branch, or the default
+// branch if it is blank. The parsers pick the build of the branch. Without
+// a requested branch they pick the build of the configured default branch
+// if the URL is for the branch or the provider lists the builds of every
+// branch, otherwise the latest build.
+func branchStatuses(projectConfig ProjectConfig, branch string) []StatusConfig {
+	requested := branch != ""
+	if !requested {
+		branch = projectConfig.DefaultBranch
+	}
+	urlBranch := branch
+	if urlBranch == "" {
+		urlBranch = defaultBranchName
+	}
+	statuses := make([]StatusConfig, len(projectConfig.Statuses))
+	for i, status := range projectConfig.Statuses {
+		scoped := strings.Contains(status.URL, branchPlaceholder)
+		status.URL = strings.Replace(status.URL, branchPlaceholder, url.PathEscape(urlBranch), -1)
+		if requested || scoped || branchListProviders[strings.ToLower(status.Provider)] {
+			status.branch = branch
+		}
+		status.untracked = !trackedBranch(projectConfig, branch)
+		statuses[i] = status
+	}
+	return statuses
+}
+
+// trackedBranch checks if a branch is the default branch or one of the
+// tracked branches of a project
+func trackedBranch(projectConfig ProjectConfig, branch string) bool {
+	if branch == "" || branch == projectConfig.DefaultBranch {
+		return true
+	}
+	for _, tracked := range projectConfig.Branches {
+		if tracked == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// branchKey is the cache key of the statuses of a branch of a project, the
+// site qualified slug for the default statuses
+func branchKey(site *site, project string, branch string) string {
+	if branch == "" {
+		return site.key(project)
+	}
+	return site.key(project) + branchSeparator + branch
+}
+
+// requestBranch returns the branch requested with ?branch=, blank for the
+// default statuses. Invalid branches are answered with a bad request.
+func (badger *Badger) requestBranch(w http.ResponseWriter, r *http.Request) (string, bool) {
+	branch := r.URL.Query().Get("branch")
+	if branch == "" {
+		return "", true
+	}
+	if !validBranch(branch) {
+		badger.log.Warning("Invalid branch '%s' requested", branch)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(fmt.Sprintf("Invalid branch '%s'", branch)))
+		return "", false
+	}
+	return branch, true
+}
+
+// trackedBranches returns the statuses of the tracked branches of a project
+func (badger *Badger) trackedBranches(site *site, project string, projectConfig ProjectConfig) []BranchStatus {
+	branches := make([]BranchStatus, 0, len(projectConfig.Branches))
+	for _, branch := range projectConfig.Branches {
+		overall, providers := badger.fetchBranchStatuses(site, project, projectConfig, branch)
+		branches = append(branches, BranchStatus{
+			Name:      branch,
+			Overall:   overall,
+			Providers: providers,
+		})
+	}
+	return branches
+}
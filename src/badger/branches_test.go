@@ -0,0 +1,189 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	badger "."
+)
+
+const travisCIBuildsJSON = `[
+    {"result": 1, "branch": "release-2.x", "message": "Fix the release", "finished_at": "2016-08-25T11:07:04Z"},
+    {"result": 0, "branch": "master", "message": "Clean up comments", "finished_at": "2016-08-25T10:46:58Z"}
+]`
+
+func TestBranches(t *testing.T) {
+	var lock sync.Mutex
+	requests := make(map[string]int)
+	travis := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		requests["travis"]++
+		lock.Unlock()
+		w.Write([]byte(travisCIBuildsJSON))
+	}))
+	defer travis.Close()
+	appVeyor := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		branch := strings.TrimPrefix(r.URL.EscapedPath(), "/branch/")
+		lock.Lock()
+		requests[branch]++
+		lock.Unlock()
+		w.Write([]byte(`{"build": {"status": "success", "branch": "` + strings.Replace(branch, "%2F", "/", -1) + `"}}`))
+	}))
+	defer appVeyor.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeProjectFile(t, dir, "sample.bbproj", `{
+    "Name": "Sample",
+    "DefaultBranch": "master",
+    "Branches": ["release-2.x", "feature/login"],
+    "Statuses": [
+        {"Provider": "TravisCI", "Url": "`+travis.URL+`"},
+        {"Provider": "AppVeyor", "Url": "`+appVeyor.URL+`/branch/{branch}"}
+    ]
+}`)
+	// AppVeyor returns the latest build of any branch without {branch}
+	writeProjectFile(t, dir, "latest.bbproj", `{
+    "Name": "Latest",
+    "DefaultBranch": "master",
+    "Statuses": [{"Provider": "AppVeyor", "Url": "`+appVeyor.URL+`/latest"}]
+}`)
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: 8000},
+		Fetch:        badger.FetchConfig{Retries: -1},
+		ProjectsPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+	get := func(path string, code int) []byte {
+		recorder := httptest.NewRecorder()
+		badgerBadger.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if recorder.Code != code {
+			t.Errorf("Code should be %d and not %d", code, recorder.Code)
+		}
+		return recorder.Body.Bytes()
+	}
+
+	t.Run("Status", func(t *testing.T) {
+		overall := `Overall status: <span class="%s">%s</span>`
+		body := string(get("/sample/status?branch=release-2.x", http.StatusOK))
+		if !strings.Contains(body, fmt.Sprintf(overall, "Failing", "Failing")) {
+			t.Errorf("Status should be the failing build of the branch:\n%s", body)
+		}
+		body = string(get("/sample/status", http.StatusOK))
+		if !strings.Contains(body, fmt.Sprintf(overall, "Passing", "Passing")) || !strings.Contains(body, "Clean up comments") {
+			t.Errorf("Status should be the passing build of the default branch:\n%s", body)
+		}
+	})
+
+	t.Run("Placeholder", func(t *testing.T) {
+		lock.Lock()
+		defer lock.Unlock()
+		for _, branch := range []string{"master", "release-2.x"} {
+			if requests[branch] != 1 {
+				t.Errorf("Branch '%s' should be requested once and not %d times", branch, requests[branch])
+			}
+		}
+	})
+
+	t.Run("Cached", func(t *testing.T) {
+		get("/sample/status?branch=release-2.x", http.StatusOK)
+		lock.Lock()
+		defer lock.Unlock()
+		if requests["travis"] != 2 {
+			t.Errorf("Branch statuses should be cached, Travis CI was requested %d times", requests["travis"])
+		}
+	})
+
+	t.Run("InvalidBranch", func(t *testing.T) {
+		get("/sample/status?branch=../secret", http.StatusBadRequest)
+		get("/sample?branch=-rf", http.StatusBadRequest)
+	})
+
+	t.Run("Page", func(t *testing.T) {
+		body := string(get("/sample", http.StatusOK))
+		for _, expected := range []string{
+			`<th><a href="/sample?branch=release-2.x">release-2.x</a></th>`,
+			`<th><a href="/sample?branch=feature%2flogin">feature/login</a></th>`,
+			`<td><span class="Failing">Failing</span></td>`,
+		} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("Page should contain '%s':\n%s", expected, body)
+			}
+		}
+		lock.Lock()
+		defer lock.Unlock()
+		if requests["feature%2Flogin"] != 1 {
+			t.Errorf("Branch placeholder should be escaped, requests were %v", requests)
+		}
+	})
+
+	t.Run("Events", func(t *testing.T) {
+		if !strings.Contains(string(get("/sample", http.StatusOK)), "EventSource") {
+			t.Errorf("Default branch page should subscribe to the status events")
+		}
+		if strings.Contains(string(get("/sample?branch=release-2.x", http.StatusOK)), "EventSource") {
+			t.Errorf("Branch page should not subscribe to the default branch status events")
+		}
+	})
+
+	t.Run("DefaultBranchLatestBuild", func(t *testing.T) {
+		body := string(get("/latest/status", http.StatusOK))
+		if !strings.Contains(body, `Overall status: <span class="Passing">Passing</span>`) {
+			t.Errorf("Status should be the latest build without a branch in the URL:\n%s", body)
+		}
+	})
+
+	t.Run("UntrackedBranchesNotKept", func(t *testing.T) {
+		lastGood := regexp.MustCompile(`(?m)^badger_last_good_results (\d+)$`)
+		count := func() string {
+			match := lastGood.FindStringSubmatch(string(get("/metrics", http.StatusOK)))
+			if match == nil {
+				t.Fatalf("Metrics should contain badger_last_good_results")
+			}
+			return match[1]
+		}
+		before := count()
+		for i := 0; i < 20; i++ {
+			get(fmt.Sprintf("/sample/status?branch=untracked-%d", i), http.StatusOK)
+		}
+		if after := count(); after != before {
+			t.Errorf("Last good results should stay at %s and not grow to %s", before, after)
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		validationErrors := badger.ValidateProject(badger.ProjectConfig{
+			Name:          "Sample",
+			DefaultBranch: "main..",
+			Branches:      []string{"release", "release"},
+			Statuses:      []badger.StatusConfig{{Provider: "AppVeyor", URL: "https://ci.appveyor.com"}},
+		})
+		if len(validationErrors) != 2 || validationErrors[0].Field != "DefaultBranch" || validationErrors[1].Field != "Branches[1]" {
+			t.Errorf("Branches should be invalid, got %v", validationErrors)
+		}
+	})
+}
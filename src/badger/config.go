@@ -0,0 +1,455 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	yaml "gopkg.in/yaml.v3"
+)
+
+const (
+	// ConfigFormatJSON is the constant for JSON config files
+	ConfigFormatJSON = "json"
+	// ConfigFormatYAML is the constant for YAML config files
+	ConfigFormatYAML = "yaml"
+	// ConfigFormatTOML is the constant for TOML config files
+	ConfigFormatTOML = "toml"
+)
+
+// projectFileSuffixes lists the accepted project file names
+var projectFileSuffixes = []string{".bbproj", ".bbproj.yaml", ".bbproj.yml", ".bbproj.toml"}
+
+// yamlLinePattern extracts the line number from a YAML error message
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// configSource is a config file normalised to JSON, so that every format
+// decodes into the config structs with the same semantics. It keeps a map of
+// field paths back to the lines in the original file for error reporting.
+type configSource struct {
+	fileName string
+	format   string
+	data     []byte
+	// lines maps lower cased field paths, ie. statuses[1].url, to lines
+	lines map[string]int
+}
+
+// ConfigFormat returns the config format for a file based on its extension
+func ConfigFormat(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".yaml", ".yml":
+		return ConfigFormatYAML
+	case ".toml":
+		return ConfigFormatTOML
+	default:
+		return ConfigFormatJSON
+	}
+}
+
+// IsProjectFile checks if the file name is that of a project file
+func IsProjectFile(fileName string) bool {
+	for _, suffix := range projectFileSuffixes {
+		if strings.HasSuffix(fileName, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProjectFiles lists the paths of all the project files in projectsPath
+func ProjectFiles(projectsPath string) ([]string, error) {
+	files, err := ioutil.ReadDir(projectsPath)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, file := range files {
+		if file.IsDir() || !IsProjectFile(file.Name()) {
+			continue
+		}
+		paths = append(paths, filepath.Join(projectsPath, file.Name()))
+	}
+	return paths, nil
+}
+
+// LoadConfig reads the main Badger config from a JSON, YAML or TOML file
+func LoadConfig(path string) (Config, error) {
+	var config Config
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	source, validationError := newConfigSource(path, raw)
+	if validationError != nil {
+		return config, validationError
+	}
+	validationError = source.decode(&config, false)
+	if validationError != nil {
+		return config, validationError
+	}
+	return config, nil
+}
+
+// newConfigSource converts the raw file contents to JSON based on the
+// format of the file name. Environment variable and secret file references
+// in string values are resolved, see resolveReferences.
+func newConfigSource(fileName string, raw []byte) (*configSource, *ValidationError) {
+	source := &configSource{
+		fileName: fileName,
+		format:   ConfigFormat(fileName),
+		lines:    make(map[string]int),
+	}
+	var value interface{}
+	switch source.format {
+	case ConfigFormatYAML:
+		var document yaml.Node
+		err := yaml.Unmarshal(raw, &document)
+		if err != nil {
+			return nil, source.yamlError(err)
+		}
+		err = document.Decode(&value)
+		if err != nil {
+			return nil, source.yamlError(err)
+		}
+		yamlFieldLines(&document, "", source.lines)
+
+	case ConfigFormatTOML:
+		var table map[string]interface{}
+		_, err := toml.Decode(string(raw), &table)
+		if err != nil {
+			validationError := &ValidationError{File: fileName, Message: err.Error()}
+			if parseError, ok := err.(toml.ParseError); ok {
+				validationError.Line = parseError.Position.Line
+				validationError.Message = parseError.Message
+				if validationError.Message == "" {
+					validationError.Message = parseError.Error()
+				}
+			}
+			return nil, validationError
+		}
+		value = table
+		source.lines = tomlFieldLines(raw)
+
+	default:
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		// Keep numbers as written so they decode exactly as before
+		decoder.UseNumber()
+		err := decoder.Decode(&value)
+		if err != nil {
+			validationError := &ValidationError{File: fileName, Message: err.Error()}
+			if syntaxError, ok := err.(*json.SyntaxError); ok {
+				// The offset is just past the offending character
+				validationError.Line = lineAtOffset(raw, syntaxError.Offset-1)
+			}
+			return nil, validationError
+		}
+		// Only a single object is allowed per file
+		if _, err := decoder.Token(); err != io.EOF {
+			return nil, &ValidationError{
+				File:    fileName,
+				Line:    lineAtOffset(raw, decoder.InputOffset()),
+				Message: "unexpected data after the top level object",
+			}
+		}
+		for field, offset := range jsonFieldOffsets(raw) {
+			source.lines[field] = lineAtOffset(raw, offset)
+		}
+	}
+
+	value, referenceError := resolveReferences(value, "")
+	if referenceError != nil {
+		referenceError.File = fileName
+		referenceError.Line = source.line(referenceError.Field)
+		return nil, referenceError
+	}
+	var err error
+	source.data, err = json.Marshal(value)
+	if err != nil {
+		return nil, &ValidationError{File: fileName, Message: err.Error()}
+	}
+	return source, nil
+}
+
+// decode decodes the source into target. When strict is set, fields that
+// do not exist in target are reported as errors.
+func (source *configSource) decode(target interface{}, strict bool) *ValidationError {
+	decoder := json.NewDecoder(bytes.NewReader(source.data))
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+	err := decoder.Decode(target)
+	if err != nil {
+		validationError := &ValidationError{
+			File:    source.fileName,
+			Message: err.Error(),
+		}
+		if typeError, ok := err.(*json.UnmarshalTypeError); ok {
+			validationError.Field = normaliseFieldPath(typeError.Field)
+			validationError.Line = source.line(validationError.Field)
+			validationError.Message = fmt.Sprintf("expected %s but found %s", typeError.Type.String(), typeError.Value)
+		} else if strings.HasPrefix(err.Error(), "json: unknown field ") {
+			// Unknown fields are reported without a location
+			key := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), "\"")
+			validationError.Message = fmt.Sprintf("unknown field '%s'", key)
+			validationError.Line = source.keyLine(key)
+		}
+		return validationError
+	}
+	return nil
+}
+
+// line returns the line where field is defined, falling back to the closest
+// parent that exists in the file. Returns 0 if nothing matched.
+func (source *configSource) line(field string) int {
+	field = strings.ToLower(field)
+	for field != "" {
+		if line, ok := source.lines[field]; ok {
+			return line
+		}
+		cut := strings.LastIndexAny(field, ".[")
+		if cut < 0 {
+			break
+		}
+		field = field[:cut]
+	}
+	return 0
+}
+
+// keyLine returns the first line where a key with the given name is defined
+func (source *configSource) keyLine(key string) int {
+	key = strings.ToLower(key)
+	line := 0
+	for field, fieldLine := range source.lines {
+		if field != key && !strings.HasSuffix(field, "."+key) {
+			continue
+		}
+		if line == 0 || fieldLine < line {
+			line = fieldLine
+		}
+	}
+	return line
+}
+
+// yamlError converts a YAML parse error into a validation error
+func (source *configSource) yamlError(err error) *ValidationError {
+	validationError := &ValidationError{
+		File:    source.fileName,
+		Message: strings.TrimPrefix(err.Error(), "yaml: "),
+	}
+	match := yamlLinePattern.FindStringSubmatch(err.Error())
+	if match != nil {
+		validationError.Line, _ = strconv.Atoi(match[1])
+		validationError.Message = strings.TrimPrefix(validationError.Message, match[0]+": ")
+	}
+	return validationError
+}
+
+// normaliseFieldPath converts encoding/json field paths, ie. Statuses.1.Url,
+// to the ValidationError notation Statuses[1].Url
+func normaliseFieldPath(field string) string {
+	parts := strings.Split(field, ".")
+	var path string
+	for _, part := range parts {
+		if _, err := strconv.Atoi(part); err == nil {
+			path += "[" + part + "]"
+			continue
+		}
+		if path != "" {
+			path += "."
+		}
+		path += part
+	}
+	return path
+}
+
+// yamlFieldLines maps each lower cased field path in the YAML node to the
+// line where it is defined
+func yamlFieldLines(node *yaml.Node, path string, lines map[string]int) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			yamlFieldLines(child, path, lines)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := strings.ToLower(node.Content[i].Value)
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			lines[field] = node.Content[i].Line
+			yamlFieldLines(node.Content[i+1], field, lines)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			field := fmt.Sprintf("%s[%d]", path, i)
+			lines[field] = child.Line
+			yamlFieldLines(child, field, lines)
+		}
+	}
+}
+
+// tomlFieldLines maps each lower cased field path in the TOML document to
+// the line where it is defined. Tables, arrays of tables and key/value pairs
+// are mapped, values inside inline tables fall back to their key.
+func tomlFieldLines(data []byte) map[string]int {
+	lines := make(map[string]int)
+	// Number of entries seen for each array of tables
+	arrayCounts := make(map[string]int)
+
+	// resolve turns a dotted table name into a field path, indexing into
+	// any array of tables along the way
+	resolve := func(name string) string {
+		var path string
+		for _, part := range strings.Split(name, ".") {
+			part = strings.ToLower(strings.Trim(strings.TrimSpace(part), `"'`))
+			if path != "" {
+				path += "."
+			}
+			path += part
+			if count, ok := arrayCounts[path]; ok {
+				path = fmt.Sprintf("%s[%d]", path, count-1)
+			}
+		}
+		return path
+	}
+
+	table := ""
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[["):
+			end := strings.Index(line, "]]")
+			if end < 0 {
+				continue
+			}
+			name := line[2:end]
+			parent := ""
+			if cut := strings.LastIndex(name, "."); cut >= 0 {
+				parent = resolve(name[:cut]) + "."
+				name = name[cut+1:]
+			}
+			arrayPath := parent + strings.ToLower(strings.Trim(strings.TrimSpace(name), `"'`))
+			if _, ok := lines[arrayPath]; !ok {
+				lines[arrayPath] = lineNumber
+			}
+			arrayCounts[arrayPath]++
+			table = fmt.Sprintf("%s[%d]", arrayPath, arrayCounts[arrayPath]-1)
+			lines[table] = lineNumber
+		case strings.HasPrefix(line, "["):
+			end := strings.Index(line, "]")
+			if end < 0 {
+				continue
+			}
+			table = resolve(line[1:end])
+			lines[table] = lineNumber
+		default:
+			equals := strings.Index(line, "=")
+			if equals < 0 {
+				continue
+			}
+			field := table
+			for _, part := range strings.Split(line[:equals], ".") {
+				if field != "" {
+					field += "."
+				}
+				field += strings.ToLower(strings.Trim(strings.TrimSpace(part), `"'`))
+			}
+			lines[field] = lineNumber
+		}
+	}
+	return lines
+}
+
+// jsonFieldOffsets maps each lower cased field path in the JSON document, in
+// the same notation as ValidationError.Field, to the offset where it is defined
+func jsonFieldOffsets(data []byte) map[string]int64 {
+	offsets := make(map[string]int64)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	var walk func(path string) error
+	walk = func(path string) error {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		if path != "" {
+			offsets[path] = offset
+		}
+		delim, ok := token.(json.Delim)
+		if !ok {
+			return nil
+		}
+		switch delim {
+		case '{':
+			for decoder.More() {
+				keyOffset := decoder.InputOffset()
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := keyToken.(string)
+				// Field names are matched case insensitively, as in encoding/json
+				field := strings.ToLower(key)
+				if path != "" {
+					field = path + "." + field
+				}
+				err = walk(field)
+				if err != nil {
+					return err
+				}
+				// Point at the key rather than the value
+				offsets[field] = keyOffset
+			}
+		case '[':
+			for index := 0; decoder.More(); index++ {
+				err := walk(fmt.Sprintf("%s[%d]", path, index))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		// Consume the closing delimiter
+		_, err = decoder.Token()
+		return err
+	}
+	walk("")
+	return offsets
+}
+
+// lineAtOffset returns the 1-based line number at the byte offset in data
+func lineAtOffset(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	// Skip leading whitespace so offsets taken before a token
+	// point at the token itself
+	for offset < int64(len(data)) && strings.ContainsRune(" \t\r\n,:", rune(data[offset])) {
+		offset++
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
@@ -0,0 +1,231 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	badger "."
+)
+
+func TestLoadConfigFormats(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	jsonPath := writeProjectFile(t, dir, "config.json", `{
+    "Log": {"Level": "INFO", "Console": {"Enabled": true, "Format": "%{message}"}},
+    "Server": {"IP": "0.0.0.0", "Port": 8000, "BasePath": "/badger"},
+    "ProjectsPath": "projects"
+}`)
+	yamlPath := writeProjectFile(t, dir, "config.yaml", `# Comments are allowed
+log:
+  level: INFO
+  console:
+    enabled: true
+    format: "%{message}"
+server:
+  ip: 0.0.0.0
+  port: 8000
+  basePath: /badger
+projectsPath: projects
+`)
+	tomlPath := writeProjectFile(t, dir, "config.toml", `# Comments are allowed
+ProjectsPath = "projects"
+
+[Log]
+Level = "INFO"
+
+[Log.Console]
+Enabled = true
+Format = "%{message}"
+
+[Server]
+IP = "0.0.0.0"
+Port = 8000
+BasePath = "/badger"
+`)
+
+	expected, err := badger.LoadConfig(jsonPath)
+	if err != nil {
+		t.Fatalf("Unable to load JSON config: %s", err.Error())
+	}
+	for _, path := range []string{yamlPath, tomlPath} {
+		config, err := badger.LoadConfig(path)
+		if err != nil {
+			t.Errorf("Unable to load config '%s': %s", path, err.Error())
+			continue
+		}
+		if !reflect.DeepEqual(config, expected) {
+			t.Errorf("Config '%s' should equal the JSON config, got %+v", path, config)
+		}
+	}
+}
+
+func TestValidateProjectFilesLines(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	yamlPath := writeProjectFile(t, dir, "sample.bbproj.yaml", `name: Sample
+statuses:
+  - provider: AppVeyor
+    url: https://ci.appveyor.com/api/projects/donovansolms/ioRPC
+  - provider: Jenkins
+    url: https://jenkins.example.com
+`)
+	tomlPath := writeProjectFile(t, dir, "other.bbproj.toml", `Name = "Other"
+
+[[Statuses]]
+Provider = "AppVeyor"
+Url = "https://ci.appveyor.com/api/projects/donovansolms/ioRPC"
+
+[[Statuses]]
+Provider = "TravisCI"
+Url = "ftp://travis-ci.org"
+`)
+
+	validationErrors := badger.ValidateProjectFiles([]string{yamlPath, tomlPath}, "")
+	expected := []string{
+		yamlPath + ":5: Statuses[1].Provider: unknown provider 'Jenkins'",
+		tomlPath + ":9: Statuses[1].Url: URL scheme must be http or https",
+	}
+	if len(validationErrors) != len(expected) {
+		t.Fatalf("Expected %d errors and not %d: %v", len(expected), len(validationErrors), validationErrors)
+	}
+	for i, validationError := range validationErrors {
+		if validationError.Error() != expected[i] {
+			t.Errorf("Error should be '%s' and not '%s'", expected[i], validationError.Error())
+		}
+	}
+}
+
+func TestLoadConfigReferences(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("BADGER_TEST_HOST", "badger.example.com")
+	defer os.Unsetenv("BADGER_TEST_HOST")
+	os.Setenv("BADGER_TEST_PROXY_KEY", "pr0xy-k3y")
+	defer os.Unsetenv("BADGER_TEST_PROXY_KEY")
+	secretPath := writeProjectFile(t, dir, "token", "s3cr3t-t0ken\n")
+	configPath := writeProjectFile(t, dir, "config.yaml", `server:
+  ip: ${BADGER_TEST_HOST}
+  port: 8000
+  adminToken: file:`+secretPath+`
+fetch:
+  proxyURL: secret:http://proxy.example.com/?key=${BADGER_TEST_PROXY_KEY}
+`)
+
+	config, err := badger.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Unable to load config: %s", err.Error())
+	}
+
+	t.Run("Environment", func(t *testing.T) {
+		if config.Server.IP != "badger.example.com" {
+			t.Errorf("IP should be '%s' and not '%s'", "badger.example.com", config.Server.IP)
+		}
+	})
+
+	t.Run("SecretFile", func(t *testing.T) {
+		if config.Server.AdminToken != "s3cr3t-t0ken" {
+			t.Errorf("AdminToken should be '%s' and not '%s'", "s3cr3t-t0ken", config.Server.AdminToken)
+		}
+	})
+
+	t.Run("Redact", func(t *testing.T) {
+		redacted := badger.Redact("GET https://ci.example.com/?token=s3cr3t-t0ken failed")
+		expected := "GET https://ci.example.com/?token=" + badger.RedactedPlaceholder + " failed"
+		if redacted != expected {
+			t.Errorf("Redacted message should be '%s' and not '%s'", expected, redacted)
+		}
+	})
+
+	t.Run("RedactSecretPrefix", func(t *testing.T) {
+		if config.Fetch.ProxyURL != "http://proxy.example.com/?key=pr0xy-k3y" {
+			t.Errorf("ProxyURL should be '%s' and not '%s'", "http://proxy.example.com/?key=pr0xy-k3y", config.Fetch.ProxyURL)
+		}
+		redacted := badger.Redact("dial " + config.Fetch.ProxyURL + " failed")
+		expected := "dial http://proxy.example.com/?key=" + badger.RedactedPlaceholder + " failed"
+		if redacted != expected {
+			t.Errorf("Redacted message should be '%s' and not '%s'", expected, redacted)
+		}
+	})
+
+	t.Run("MissingVariable", func(t *testing.T) {
+		missingPath := writeProjectFile(t, dir, "missing.json", "{\n    \"Server\": {\n        \"IP\": \"${BADGER_TEST_MISSING}\"\n    }\n}")
+		_, err := badger.LoadConfig(missingPath)
+		expected := missingPath + ":3: Server.IP: environment variable 'BADGER_TEST_MISSING' is not set"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Error should be '%s' and not '%v'", expected, err)
+		}
+	})
+}
+
+func TestRedactFetchError(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	os.Setenv("BADGER_TEST_URL_TOKEN", "url-t0ken")
+	defer os.Unsetenv("BADGER_TEST_URL_TOKEN")
+	os.Setenv("BADGER_TEST_HEADER_TOKEN", "h3ader-t0ken")
+	defer os.Unsetenv("BADGER_TEST_HEADER_TOKEN")
+	writeProjectFile(t, dir, "sample.bbproj.yaml", `name: Sample
+statuses:
+  - provider: AppVeyor
+    url: http://127.0.0.1:1/api?token=${BADGER_TEST_URL_TOKEN}
+    headers:
+      Authorization: Bearer ${BADGER_TEST_HEADER_TOKEN}
+`)
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: 8000},
+		Fetch:        badger.FetchConfig{Retries: -1},
+		ProjectsPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+	project, ok := badgerBadger.Project("sample")
+	if !ok {
+		t.Fatalf("Project 'sample' should be loaded")
+	}
+	status := project.Statuses[0]
+	fetchError := fmt.Sprintf("Get \"%s\" with 'Authorization: %s': connection refused", status.URL, status.Headers["Authorization"])
+	redacted := badger.Redact(fetchError)
+	for _, secret := range []string{"url-t0ken", "h3ader-t0ken"} {
+		if strings.Contains(redacted, secret) {
+			t.Errorf("Redacted error should not contain '%s': %s", secret, redacted)
+		}
+	}
+	expected := "Get \"http://127.0.0.1:1/api?token=" + badger.RedactedPlaceholder + "\" with 'Authorization: Bearer " + badger.RedactedPlaceholder + "': connection refused"
+	if redacted != expected {
+		t.Errorf("Redacted error should be '%s' and not '%s'", expected, redacted)
+	}
+}
@@ -0,0 +1,306 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// EventsContentType is the content type of the event streams
+	EventsContentType = "text/event-stream"
+	// eventStatus is the name of the status change events
+	eventStatus = "status"
+	// eventHeartbeatInterval is how often a comment is sent on idle streams
+	// so that proxies don't close them
+	eventHeartbeatInterval = 15 * time.Second
+	// eventRetry is how long clients wait before reconnecting
+	eventRetry = 5 * time.Second
+	// eventHistory is the number of events kept to replay on reconnection
+	eventHistory = 256
+	// eventBuffer is the number of events queued per client, a client that
+	// falls further behind is disconnected and replays on reconnection
+	eventBuffer = 32
+)
+
+// StatusEvent is sent on the event streams when a provider status changes
+type StatusEvent struct {
+	ID             uint64    `json:"ID"`
+	Project        string    `json:"Project"`
+	Provider       string    `json:"Provider"`
+	ProperName     string    `json:"ProperName"`
+	Status         string    `json:"Status"`
+	PreviousStatus string    `json:"PreviousStatus"`
+	Overall        string    `json:"Overall"`
+	Time           time.Time `json:"Time"`
+	// site is the site of the project
+	site *site
+}
+
+// eventSubscriber is a connected event stream client
+type eventSubscriber struct {
+	site *site
+	// project is blank to receive the events of all the site's projects
+	project string
+	events  chan StatusEvent
+}
+
+// matches checks if the subscriber wants the event
+func (subscriber *eventSubscriber) matches(event StatusEvent) bool {
+	return subscriber.site == event.site && (subscriber.project == "" || subscriber.project == event.Project)
+}
+
+// eventHub fans the status events out to the subscribers and keeps the
+// recent events for clients that reconnect
+type eventHub struct {
+	sync.Mutex
+	lastID      uint64
+	history     []StatusEvent
+	subscribers map[*eventSubscriber]bool
+	closed      bool
+}
+
+// newEventHub creates an event hub. The IDs start at the current time so
+// that they keep increasing when Badger restarts.
+func newEventHub() *eventHub {
+	return &eventHub{
+		lastID:      uint64(time.Now().UnixNano()),
+		history:     make([]StatusEvent, 0, eventHistory),
+		subscribers: make(map[*eventSubscriber]bool),
+	}
+}
+
+// publish sends an event to the subscribers that want it
+func (hub *eventHub) publish(event StatusEvent) {
+	hub.Lock()
+	defer hub.Unlock()
+	hub.lastID++
+	event.ID = hub.lastID
+	if len(hub.history) == eventHistory {
+		hub.history = append(hub.history[:0], hub.history[1:]...)
+	}
+	hub.history = append(hub.history, event)
+	for subscriber := range hub.subscribers {
+		if !subscriber.matches(event) {
+			continue
+		}
+		select {
+		case subscriber.events <- event:
+		default:
+			delete(hub.subscribers, subscriber)
+			close(subscriber.events)
+		}
+	}
+}
+
+// subscribe adds a subscriber and returns the events it missed since
+// lastID, none if lastID is zero. It fails once the hub is closed.
+func (hub *eventHub) subscribe(site *site, project string, lastID uint64) (*eventSubscriber, []StatusEvent, bool) {
+	hub.Lock()
+	defer hub.Unlock()
+	if hub.closed {
+		return nil, nil, false
+	}
+	subscriber := &eventSubscriber{
+		site:    site,
+		project: project,
+		events:  make(chan StatusEvent, eventBuffer),
+	}
+	missed := make([]StatusEvent, 0)
+	if lastID > 0 {
+		for _, event := range hub.history {
+			if event.ID > lastID && subscriber.matches(event) {
+				missed = append(missed, event)
+			}
+		}
+	}
+	hub.subscribers[subscriber] = true
+	return subscriber, missed, true
+}
+
+// unsubscribe removes a subscriber
+func (hub *eventHub) unsubscribe(subscriber *eventSubscriber) {
+	hub.Lock()
+	defer hub.Unlock()
+	if hub.subscribers[subscriber] {
+		delete(hub.subscribers, subscriber)
+		close(subscriber.events)
+	}
+}
+
+// count returns the number of subscribers
+func (hub *eventHub) count() int {
+	hub.Lock()
+	defer hub.Unlock()
+	return len(hub.subscribers)
+}
+
+// close disconnects all the subscribers and refuses new ones, ie. while
+// shutting down since open streams would keep the server busy
+func (hub *eventHub) close() {
+	hub.Lock()
+	defer hub.Unlock()
+	hub.closed = true
+	for subscriber := range hub.subscribers {
+		delete(hub.subscribers, subscriber)
+		close(subscriber.events)
+	}
+}
+
+// open accepts subscribers again after close
+func (hub *eventHub) open() {
+	hub.Lock()
+	defer hub.Unlock()
+	hub.closed = false
+}
+
+// publishChanges publishes an event for every provider of a project whose
+// status differs from the previously cached statuses. Nothing is published
+// for the first statuses of a project, ie. at startup or after a reload.
+func (badger *Badger) publishChanges(site *site, project string, entry cachedStatuses, previous cachedStatuses, hadPrevious bool) {
+	if !hadPrevious {
+		return
+	}
+	providers := make([]string, 0, len(entry.providers))
+	for provider := range entry.providers {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		providerStatus := entry.providers[provider]
+		previousStatus := previous.providers[provider].Status
+		if providerStatus.Status == previousStatus {
+			continue
+		}
+		badger.events.publish(StatusEvent{
+			Project:        project,
+			Provider:       provider,
+			ProperName:     providerStatus.ProperName,
+			Status:         providerStatus.Status,
+			PreviousStatus: previousStatus,
+			Overall:        entry.overall.Status,
+			Time:           entry.fetched,
+			site:           site,
+		})
+	}
+}
+
+// EventsHandler handles calls to /events and streams the status changes of
+// all the projects as Server-Sent Events
+func (badger *Badger) EventsHandler(w http.ResponseWriter, r *http.Request) {
+	badger.streamEvents(w, r, "")
+}
+
+// ProjectEventsHandler handles calls to /{project}/events and streams the
+// status changes of the project as Server-Sent Events
+func (badger *Badger) ProjectEventsHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	project := strings.ToLower(vars["project"])
+	if _, ok := badger.siteProject(badger.routeFor(r).site, project); !ok {
+		badger.log.Error("Project config not found for project '%s'", project)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(fmt.Sprintf("Project config not found for project '%s'", project)))
+		return
+	}
+	badger.streamEvents(w, r, project)
+}
+
+// lastEventID returns the ID of the last event the client received from
+// the Last-Event-ID header, or the lastEventId query for clients that
+// can't set headers. It is zero for new clients.
+func lastEventID(r *http.Request) uint64 {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("lastEventId")
+	}
+	lastID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return lastID
+}
+
+// streamEvents streams the status events of project, or all the projects
+// if it is blank, until the client disconnects or Badger shuts down
+func (badger *Badger) streamEvents(w http.ResponseWriter, r *http.Request, project string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Streaming is not supported"))
+		return
+	}
+	subscriber, missed, ok := badger.events.subscribe(badger.routeFor(r).site, project, lastEventID(r))
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("Badger is shutting down"))
+		return
+	}
+	defer badger.events.unsubscribe(subscriber)
+	badger.log.Debug("Event stream opened for '%s' with %d missed event(s)", project, len(missed))
+
+	w.Header().Set("Content-Type", EventsContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	// Stops nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", eventRetry/time.Millisecond)
+	for _, event := range missed {
+		if writeEvent(w, event) != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventHeartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			badger.log.Debug("Event stream for '%s' closed by the client", project)
+			return
+		case event, open := <-subscriber.events:
+			if !open {
+				// Badger is shutting down or the client fell behind
+				return
+			}
+			if writeEvent(w, event) != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeEvent writes a status event in the event stream format
+func writeEvent(w http.ResponseWriter, event StatusEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, eventStatus, data)
+	return err
+}
@@ -0,0 +1,205 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	badger "."
+)
+
+// eventStream reads the status events of an event stream until it ends
+func eventStream(body io.Reader) <-chan badger.StatusEvent {
+	events := make(chan badger.StatusEvent, 16)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			if !strings.HasPrefix(line, "data: ") {
+				continue
+			}
+			var event badger.StatusEvent
+			if json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event) == nil {
+				events <- event
+			}
+		}
+	}()
+	return events
+}
+
+// nextEvent waits for the next event of a stream
+func nextEvent(t *testing.T, events <-chan badger.StatusEvent) badger.StatusEvent {
+	select {
+	case event, ok := <-events:
+		if !ok {
+			t.Fatalf("The event stream ended")
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatalf("No event received")
+	}
+	return badger.StatusEvent{}
+}
+
+// openEventStream requests an event stream on a connection of its own
+func openEventStream(t *testing.T, url string, lastEventID uint64) *http.Response {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lastEventID > 0 {
+		request.Header.Set("Last-Event-ID", fmt.Sprintf("%d", lastEventID))
+	}
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	response, err := client.Do(request)
+	if err != nil {
+		t.Fatalf("Unable to open the event stream: %s", err.Error())
+	}
+	return response
+}
+
+func TestEvents(t *testing.T) {
+	var failing int32
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&failing) == 1 {
+			w.Write([]byte(`{"build": {"status": "failed"}}`))
+			return
+		}
+		w.Write([]byte(appVeyorStatusJSON))
+	}))
+	defer provider.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	port := freePort(t)
+	badgerBadger := newTestBadger(t, dir, provider.URL, port)
+	server := httptest.NewServer(badgerBadger.Handler())
+	defer server.Close()
+
+	response := openEventStream(t, server.URL+"/events", 0)
+	if response.Header.Get("Content-Type") != badger.EventsContentType {
+		t.Errorf("Content-Type should be '%s' and not '%s'", badger.EventsContentType, response.Header.Get("Content-Type"))
+	}
+	events := eventStream(response.Body)
+
+	// The first statuses aren't published
+	badgerBadger.Refresh()
+	atomic.StoreInt32(&failing, 1)
+	badgerBadger.Refresh()
+	first := nextEvent(t, events)
+	if first.Project != "sample" || first.Provider != "appveyor" || first.Status != "Failing" || first.PreviousStatus != "Passing" || first.Overall != "Failing" {
+		t.Errorf("The change should publish a failing status, got %+v", first)
+	}
+	// Unchanged statuses aren't published
+	badgerBadger.Refresh()
+	atomic.StoreInt32(&failing, 0)
+	badgerBadger.Refresh()
+	second := nextEvent(t, events)
+	if second.Status != "Passing" || second.PreviousStatus != "Failing" || second.Overall != "Passing" {
+		t.Errorf("The change should publish a passing status, got %+v", second)
+	}
+	if second.ID <= first.ID {
+		t.Errorf("Event IDs should increase, got %d after %d", second.ID, first.ID)
+	}
+
+	t.Run("LastEventID", func(t *testing.T) {
+		response := openEventStream(t, server.URL+"/sample/events", first.ID)
+		defer response.Body.Close()
+		missed := nextEvent(t, eventStream(response.Body))
+		if missed.ID != second.ID {
+			t.Errorf("The missed event should be %d and not %d", second.ID, missed.ID)
+		}
+	})
+
+	t.Run("UnknownProject", func(t *testing.T) {
+		response := openEventStream(t, server.URL+"/unknown/events", 0)
+		response.Body.Close()
+		if response.StatusCode != http.StatusNotFound {
+			t.Errorf("Code should be %d and not %d", http.StatusNotFound, response.StatusCode)
+		}
+	})
+
+	t.Run("Disconnect", func(t *testing.T) {
+		response.Body.Close()
+		for i := 0; ; i++ {
+			recorder := httptest.NewRecorder()
+			badgerBadger.MetricsHandler(recorder, httptest.NewRequest("GET", "/metrics", nil))
+			if strings.Contains(recorder.Body.String(), "badger_event_subscribers 0\n") {
+				break
+			}
+			if i == 100 {
+				t.Fatalf("The subscribers should be removed when the clients disconnect")
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	})
+}
+
+func TestEventsShutdown(t *testing.T) {
+	provider := newStatusServer(t, func(r *http.Request) {})
+	defer provider.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	port := freePort(t)
+	badgerBadger := newTestBadger(t, dir, provider.URL, port)
+	started := make(chan error, 1)
+	go func() {
+		started <- badgerBadger.Start(context.Background())
+	}()
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", port)
+	for i := 0; ; i++ {
+		response, err := http.Get(baseURL + "/healthz")
+		if err == nil {
+			response.Body.Close()
+			break
+		}
+		if i == 50 {
+			t.Fatalf("Badger did not start: %s", err.Error())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	response := openEventStream(t, baseURL+"/events", 0)
+	defer response.Body.Close()
+	events := eventStream(response.Body)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := badgerBadger.Shutdown(ctx); err != nil {
+		t.Errorf("Shutdown should not wait for the event streams: %s", err.Error())
+	}
+	for range events {
+	}
+	if err := <-started; err != nil {
+		t.Errorf("Start should return without an error after a shutdown: %s", err.Error())
+	}
+}
@@ -0,0 +1,157 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultFetchTimeout is the request timeout when none is configured
+	defaultFetchTimeout = 10 * time.Second
+	// defaultMaxIdleConnsPerHost is the number of keep-alive connections
+	// kept per provider host when none is configured
+	defaultMaxIdleConnsPerHost = 4
+	// defaultIdleConnTimeout is how long an idle keep-alive connection
+	// is kept when none is configured
+	defaultIdleConnTimeout = 90 * time.Second
+)
+
+// Fetcher fetches statuses from the CI providers. A single Fetcher shares
+// its connection pool, rate limit state and circuit breakers between all
+// the projects.
+type Fetcher struct {
+	client *http.Client
+	policy fetchPolicy
+	// timeouts overrides the request timeout by lower cased provider
+	timeouts map[string]time.Duration
+	timeout  time.Duration
+	limiter  *rateLimiter
+	breaker  *circuitBreaker
+	// Metrics records the fetch counts and latencies, nil records nothing
+	Metrics *Metrics
+}
+
+// NewFetcher creates a Fetcher with a transport built from the config
+func NewFetcher(config FetchConfig) (*Fetcher, error) {
+	transport, err := newTransport(config)
+	if err != nil {
+		return nil, err
+	}
+	return NewFetcherWithTransport(config, transport), nil
+}
+
+// NewFetcherWithTransport creates a Fetcher that sends its requests using
+// transport. The transport settings in config are ignored.
+func NewFetcherWithTransport(config FetchConfig, transport http.RoundTripper) *Fetcher {
+	fetcher := &Fetcher{
+		client: &http.Client{
+			Transport: transport,
+		},
+		policy:   newFetchPolicy(config),
+		timeouts: make(map[string]time.Duration),
+		timeout:  time.Duration(config.TimeoutSeconds) * time.Second,
+		limiter:  newRateLimiter(),
+		breaker:  newCircuitBreaker(),
+	}
+	if fetcher.timeout <= 0 {
+		fetcher.timeout = defaultFetchTimeout
+	}
+	for provider, seconds := range config.ProviderTimeoutSeconds {
+		if seconds > 0 {
+			fetcher.timeouts[strings.ToLower(provider)] = time.Duration(seconds) * time.Second
+		}
+	}
+	return fetcher
+}
+
+// timeoutFor returns the request timeout for provider
+func (fetcher *Fetcher) timeoutFor(provider string) time.Duration {
+	if timeout, ok := fetcher.timeouts[strings.ToLower(provider)]; ok {
+		return timeout
+	}
+	return fetcher.timeout
+}
+
+// newTransport builds a keep-alive transport with the proxy and TLS
+// settings from the config
+func newTransport(config FetchConfig) (*http.Transport, error) {
+	proxy := http.ProxyFromEnvironment
+	if config.ProxyURL != "" {
+		proxyURL, err := url.Parse(config.ProxyURL)
+		if err != nil {
+			return nil, errors.New("Invalid proxy URL: " + err.Error())
+		}
+		if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+			return nil, errors.New("Proxy URL scheme must be http or https")
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig := &tls.Config{}
+	if len(config.CACertFiles) > 0 {
+		roots, err := x509.SystemCertPool()
+		if err != nil || roots == nil {
+			roots = x509.NewCertPool()
+		}
+		for _, caFile := range config.CACertFiles {
+			pem, err := ioutil.ReadFile(caFile)
+			if err != nil {
+				return nil, errors.New("Unable to read CA certificates: " + err.Error())
+			}
+			if !roots.AppendCertsFromPEM(pem) {
+				return nil, errors.New("No CA certificates found in '" + caFile + "'")
+			}
+		}
+		tlsConfig.RootCAs = roots
+	}
+	if config.ClientCertFile != "" || config.ClientKeyFile != "" {
+		certificate, err := tls.LoadX509KeyPair(config.ClientCertFile, config.ClientKeyFile)
+		if err != nil {
+			return nil, errors.New("Unable to load client certificate: " + err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	maxIdleConnsPerHost := config.MaxIdleConnsPerHost
+	if maxIdleConnsPerHost <= 0 {
+		maxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+	}
+	idleConnTimeout := time.Duration(config.IdleConnTimeoutSeconds) * time.Second
+	if idleConnTimeout <= 0 {
+		idleConnTimeout = defaultIdleConnTimeout
+	}
+
+	return &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   10 * time.Second,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       idleConnTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		ForceAttemptHTTP2:     true,
+	}, nil
+}
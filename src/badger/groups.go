@@ -0,0 +1,190 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"fmt"
+	"image"
+	"net/http"
+	"sort"
+	"strings"
+
+	"./parsers"
+	"github.com/gorilla/mux"
+)
+
+// groupBadges are the badge images of the aggregate group statuses
+var groupBadges = map[string]string{
+	parsers.ProviderStatusSuccess: "build-passing.png",
+	parsers.ProviderStatusFailed:  "build-failing.png",
+	parsers.ProviderStatusUnknown: "build-unknown.png",
+}
+
+// groupSlug returns the slug of a project group as used in the URLs, the
+// same way as the project slugs
+func groupSlug(group string) string {
+	return Slugify(group)
+}
+
+// groupProjects returns the name of a group of a site and its projects
+// with their cached statuses sorted by name. No projects are returned if
+// the group doesn't exist.
+func (badger *Badger) groupProjects(site *site, slug string) (string, []ProjectStatus) {
+	projects := make([]ProjectStatus, 0)
+	for projectSlug, projectConfig := range badger.siteProjects(site) {
+		if projectConfig.Group == "" || groupSlug(projectConfig.Group) != slug {
+			continue
+		}
+		projects = append(projects, badger.cachedProjectStatus(site, projectSlug, projectConfig))
+	}
+	if len(projects) == 0 {
+		return "", projects
+	}
+	sortProjectStatuses(projects, RootSortName)
+	return strings.TrimSpace(projects[0].Config.Group), projects
+}
+
+// groupOverall aggregates the overall statuses of the projects of a group
+// with the same rules as the overall status of a project. It is unknown
+// while it would pass but a project hasn't been refreshed yet.
+func groupOverall(projects []ProjectStatus) parsers.ProviderResult {
+	overall := parsers.ProviderResult{
+		ProperName: "Overall",
+		Status:     parsers.ProviderStatusSuccess,
+	}
+	waiting := false
+	for _, projectStatus := range projects {
+		if projectStatus.Fetched.IsZero() {
+			waiting = true
+			continue
+		}
+		overall.Status = aggregateStatus(overall.Status, projectStatus.Overall.Status)
+	}
+	if waiting && overall.Status == parsers.ProviderStatusSuccess {
+		overall.Status = parsers.ProviderStatusUnknown
+		overall.Error = "Waiting for the first refresh"
+	}
+	return overall
+}
+
+// siteTags returns the distinct tags of the projects of a site sorted. Tags
+// that only differ in case are listed once.
+func siteTags(projects map[string]ProjectConfig) []string {
+	all := make([]string, 0)
+	for _, projectConfig := range projects {
+		for _, tag := range projectConfig.Tags {
+			all = append(all, strings.TrimSpace(tag))
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		first, second := strings.ToLower(all[i]), strings.ToLower(all[j])
+		if first != second {
+			return first < second
+		}
+		return all[i] < all[j]
+	})
+	tags := make([]string, 0)
+	for _, tag := range all {
+		if len(tags) > 0 && strings.EqualFold(tags[len(tags)-1], tag) {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// hasTag checks if a project is tagged with tag ignoring case
+func hasTag(projectConfig ProjectConfig, tag string) bool {
+	for _, candidate := range projectConfig.Tags {
+		if strings.EqualFold(strings.TrimSpace(candidate), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// GroupPageHandler handles calls to /groups/{group} and renders the
+// projects of the group with their cached statuses on group.html
+func (badger *Badger) GroupPageHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	group := strings.ToLower(vars["group"])
+	badger.log.Debug("Request received for group page '%s'", group)
+
+	route := badger.routeFor(r)
+	groupName, projects := badger.groupProjects(route.site, group)
+	if len(projects) == 0 {
+		badger.log.Error("No projects found for group '%s'", group)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(fmt.Sprintf("No projects found for group '%s'", group)))
+		return
+	}
+
+	page, err := badger.templatesFor(route.site).lookup("group.html")
+	if err != nil {
+		badger.log.Error("Group page does not exist at '%s': %s", "group.html", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(fmt.Sprintf("Group page could not be loaded '%s': %s", "group.html", err.Error())))
+		return
+	}
+
+	pageData := GroupPageData{
+		BasePath:  route.basePath,
+		Group:     group,
+		GroupName: groupName,
+		Overall:   groupOverall(projects),
+		Projects:  projects,
+	}
+	err = page.Execute(w, pageData)
+	if err != nil {
+		badger.log.Error("Unable to execute template for group '%s': %s", group, err.Error())
+	}
+}
+
+// GroupBadgeHandler handles calls to /groups/{group}/badge and renders the
+// aggregate status of the projects of the group
+func (badger *Badger) GroupBadgeHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	group := strings.ToLower(vars["group"])
+	badger.log.Debug("Request received for group badge '%s'", group)
+
+	route := badger.routeFor(r)
+	_, projects := badger.groupProjects(route.site, group)
+	if len(projects) == 0 {
+		badger.log.Error("No projects found for group '%s'", group)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(fmt.Sprintf("No projects found for group '%s'", group)))
+		return
+	}
+
+	badgeName := groupBadges[statusClass(groupOverall(projects).Status)]
+	reader, err := badger.badges().Open(badgeName)
+	if err != nil {
+		badger.log.Error("Group badge does not exist at '%s': %s", badgeName, err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(fmt.Sprintf("Group badge could not be loaded '%s': %s", badgeName, err.Error())))
+		return
+	}
+	defer reader.Close()
+	img, _, err := image.Decode(reader)
+	if err != nil {
+		badger.log.Error("Error loading group badge: %s", err.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(fmt.Sprintf("Error loading group badge: %s", err.Error())))
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache, private")
+	w.Header().Set("Last-Modified", badger.cacheSince)
+	w.Header().Set("Expires", badger.cacheUntil)
+	writeImage(badger.log, w, img)
+}
@@ -0,0 +1,200 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	badger "."
+)
+
+// sameImage checks if a PNG has the pixels of a badge image
+func sameImage(t *testing.T, actual []byte, badgeName string) bool {
+	expectedFile, err := os.Open(filepath.Join("assets", "badges", badgeName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer expectedFile.Close()
+	expected, _, err := image.Decode(expectedFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := png.Decode(bytes.NewReader(actual))
+	if err != nil {
+		t.Fatalf("Badge should be a PNG image: %s", err.Error())
+	}
+	if decoded.Bounds().Size() != expected.Bounds().Size() {
+		return false
+	}
+	for y := 0; y < expected.Bounds().Dy(); y++ {
+		for x := 0; x < expected.Bounds().Dx(); x++ {
+			r1, g1, b1, a1 := expected.At(expected.Bounds().Min.X+x, expected.Bounds().Min.Y+y).RGBA()
+			r2, g2, b2, a2 := decoded.At(decoded.Bounds().Min.X+x, decoded.Bounds().Min.Y+y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func TestGroups(t *testing.T) {
+	passing := newStatusServer(t, func(r *http.Request) {})
+	defer passing.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"build": {"status": "failed", "message": "Break the build"}}`))
+	}))
+	defer failing.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	projects := []struct {
+		name      string
+		group     string
+		tags      string
+		statusURL string
+	}{
+		{"Alpha", "Product One", `["web"]`, passing.URL},
+		{"Beta", "Product One", `["api"]`, failing.URL},
+		{"Gamma", "", `["Web", "api"]`, passing.URL},
+		{"Delta", "Product Two", `[]`, passing.URL},
+	}
+	for _, project := range projects {
+		writeProjectFile(t, dir, strings.ToLower(project.name)+".bbproj", `{
+    "Name": "`+project.name+`",
+    "Group": "`+project.group+`",
+    "Tags": `+project.tags+`,
+    "Statuses": [{"Provider": "AppVeyor", "Url": "`+project.statusURL+`"}]
+}`)
+	}
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: 8000},
+		Fetch:        badger.FetchConfig{Retries: -1},
+		ProjectsPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+	get := func(path string, code int) []byte {
+		recorder := httptest.NewRecorder()
+		badgerBadger.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if recorder.Code != code {
+			t.Errorf("Code should be %d and not %d", code, recorder.Code)
+		}
+		return recorder.Body.Bytes()
+	}
+
+	t.Run("BeforeRefresh", func(t *testing.T) {
+		if !sameImage(t, get("/groups/product-one/badge", http.StatusOK), "build-unknown.png") {
+			t.Errorf("Group badge should be unknown before the first refresh")
+		}
+	})
+
+	t.Run("PartlyFetched", func(t *testing.T) {
+		// only fetches Alpha, Beta is still waiting for the refresh
+		get("/alpha", http.StatusOK)
+		if !sameImage(t, get("/groups/product-one/badge", http.StatusOK), "build-unknown.png") {
+			t.Errorf("Group badge should be unknown while a project hasn't been fetched")
+		}
+	})
+
+	badgerBadger.Refresh()
+
+	t.Run("Badge", func(t *testing.T) {
+		if !sameImage(t, get("/groups/product-one/badge", http.StatusOK), "build-failing.png") {
+			t.Errorf("Group badge should be failing when a project is failing")
+		}
+		if !sameImage(t, get("/groups/product-two/badge", http.StatusOK), "build-passing.png") {
+			t.Errorf("Group badge should be passing when all the projects are passing")
+		}
+	})
+
+	t.Run("Page", func(t *testing.T) {
+		body := string(get("/groups/Product-One", http.StatusOK))
+		for _, expected := range []string{
+			"<title>Product One Status Page</title>",
+			`<img src="/groups/product-one/badge"`,
+			`<a href="/alpha">Alpha</a>`,
+			`<a href="/beta">Beta</a>`,
+			`Overall status: <span class="Failing">Failing</span>`,
+		} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("Page should contain '%s':\n%s", expected, body)
+			}
+		}
+		if strings.Contains(body, "Gamma") {
+			t.Errorf("Page should only list the projects of the group:\n%s", body)
+		}
+	})
+
+	t.Run("UnknownGroup", func(t *testing.T) {
+		get("/groups/unknown", http.StatusNotFound)
+		get("/groups/unknown/badge", http.StatusNotFound)
+	})
+
+	// names lists the project and group headings of the root page in order
+	headings := regexp.MustCompile(`<h2 class="group">(?:<a href="[^"]+">)?([\w ]+)(?:</a>)?</h2>|<a href="/\w+">(\w+)</a>`)
+	names := func(body string) string {
+		found := make([]string, 0)
+		for _, match := range headings.FindAllStringSubmatch(body, -1) {
+			found = append(found, match[1]+match[2])
+		}
+		return strings.Join(found, ",")
+	}
+
+	t.Run("RootTag", func(t *testing.T) {
+		body := string(get("/?tag=WEB", http.StatusOK))
+		if actual := names(body); actual != "Alpha,Gamma" {
+			t.Errorf("Projects should be '%s' and not '%s'", "Alpha,Gamma", actual)
+		}
+		if !strings.Contains(body, `<option value="Web" selected>Web</option>`) {
+			t.Errorf("Page should select the tag:\n%s", body)
+		}
+	})
+
+	t.Run("RootGroup", func(t *testing.T) {
+		body := string(get("/?group=group", http.StatusOK))
+		expected := "Product One,Alpha,Beta,Product Two,Delta,Ungrouped,Gamma"
+		if actual := names(body); actual != expected {
+			t.Errorf("Projects should be '%s' and not '%s'", expected, actual)
+		}
+		if !strings.Contains(body, `<a href="/groups/product-one">Product One</a>`) {
+			t.Errorf("Group headings should link to the group page:\n%s", body)
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		validationErrors := badger.ValidateProject(badger.ProjectConfig{
+			Name:     "Sample",
+			Group:    "!!!",
+			Tags:     []string{"web", " "},
+			Statuses: []badger.StatusConfig{{Provider: "AppVeyor", URL: "https://ci.appveyor.com"}},
+		})
+		if len(validationErrors) != 2 || validationErrors[0].Field != "Group" || validationErrors[1].Field != "Tags[1]" {
+			t.Errorf("Group and tags should be invalid, got %v", validationErrors)
+		}
+	})
+}
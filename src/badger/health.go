@@ -0,0 +1,127 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// HealthOK is the status of a passing health check
+	HealthOK = "ok"
+	// HealthUnavailable is the status of a failing health check
+	HealthUnavailable = "unavailable"
+)
+
+// defaultPages are the templates that must parse for Badger to be ready
+var defaultPages = []string{"root.html", "default.html", "ajax.html", "group.html"}
+
+// HealthCheck is the result of a single readiness check
+type HealthCheck struct {
+	Status  string `json:"Status"`
+	Message string `json:"Message"`
+}
+
+// HealthResult is the body of the health and readiness endpoints
+type HealthResult struct {
+	Status string                 `json:"Status"`
+	Checks map[string]HealthCheck `json:"Checks,omitempty"`
+}
+
+// loadTemplates parses the templates of every site and keeps the errors
+// of the default pages for the readiness check
+func (badger *Badger) loadTemplates() {
+	var templatesErr error
+	sets := make([]*templateSet, len(badger.sites))
+	for i, site := range badger.sites {
+		sets[i] = newTemplateSet(badger.pagesFor(site), badger.devMode)
+		for _, page := range defaultPages {
+			_, err := sets[i].lookup(page)
+			if err != nil {
+				badger.log.Warning("Template '%s' of the %s site could not be parsed: %s", page, site.label(), err.Error())
+				templatesErr = err
+				break
+			}
+		}
+	}
+	badger.projectsLock.Lock()
+	defer badger.projectsLock.Unlock()
+	for i, site := range badger.sites {
+		site.templates = sets[i]
+	}
+	badger.templatesErr = templatesErr
+}
+
+// Readiness runs the readiness checks
+func (badger *Badger) Readiness() HealthResult {
+	result := HealthResult{
+		Status: HealthOK,
+		Checks: make(map[string]HealthCheck),
+	}
+	check := func(name string, ok bool, message string) {
+		status := HealthOK
+		if !ok {
+			status = HealthUnavailable
+			result.Status = HealthUnavailable
+		}
+		result.Checks[name] = HealthCheck{Status: status, Message: message}
+	}
+
+	badger.projectsLock.RLock()
+	projectCount := 0
+	for _, site := range badger.sites {
+		projectCount += len(site.projects)
+	}
+	templatesErr := badger.templatesErr
+	badger.projectsLock.RUnlock()
+
+	check("Projects", projectCount > 0, fmt.Sprintf("%d project(s) loaded", projectCount))
+	if templatesErr != nil {
+		check("Templates", false, templatesErr.Error())
+	} else {
+		check("Templates", true, "Templates parsed")
+	}
+	refreshed := badger.statuses.lastRefresh()
+	if refreshed.IsZero() {
+		check("StatusCache", false, "Waiting for the first refresh")
+	} else {
+		check("StatusCache", true, "Last refreshed "+refreshed.UTC().Format(time.RFC3339))
+	}
+	return result
+}
+
+// HealthzHandler handles calls to /healthz. It only reports that the
+// server is able to respond.
+func (badger *Badger) HealthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealthResult(w, HealthResult{Status: HealthOK})
+}
+
+// ReadyzHandler handles calls to /readyz and reports if the projects,
+// templates and statuses are available
+func (badger *Badger) ReadyzHandler(w http.ResponseWriter, r *http.Request) {
+	writeHealthResult(w, badger.Readiness())
+}
+
+// writeHealthResult writes result as JSON, with 503 if it isn't ok
+func writeHealthResult(w http.ResponseWriter, result HealthResult) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache, no-store")
+	if result.Status != HealthOK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(result)
+}
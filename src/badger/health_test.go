@@ -0,0 +1,106 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	badger "."
+)
+
+func TestReadiness(t *testing.T) {
+	server := newStatusServer(t, func(r *http.Request) {})
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeProjectFile(t, dir, "sample.bbproj", `{
+    "Name": "Sample",
+    "Statuses": [{"Provider": "AppVeyor", "Url": "`+server.URL+`"}]
+}`)
+	// A broken root page overrides the embedded one
+	pagesDir := filepath.Join(dir, "pages")
+	os.Mkdir(pagesDir, 0755)
+	brokenPage := writeProjectFile(t, pagesDir, "root.html", "{{ .Projects")
+
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: 8000},
+		ProjectsPath: dir,
+		PagesPath:    pagesDir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+
+	readiness := func() (int, badger.HealthResult) {
+		recorder := httptest.NewRecorder()
+		badgerBadger.ReadyzHandler(recorder, httptest.NewRequest("GET", "/readyz", nil))
+		var result badger.HealthResult
+		err := json.Unmarshal(recorder.Body.Bytes(), &result)
+		if err != nil {
+			t.Fatalf("Readiness should be JSON: %s", err.Error())
+		}
+		return recorder.Code, result
+	}
+
+	t.Run("Live", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		badgerBadger.HealthzHandler(recorder, httptest.NewRequest("GET", "/healthz", nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Code should be %d and not %d", http.StatusOK, recorder.Code)
+		}
+	})
+
+	t.Run("NotReady", func(t *testing.T) {
+		code, result := readiness()
+		if code != http.StatusServiceUnavailable {
+			t.Errorf("Code should be %d and not %d", http.StatusServiceUnavailable, code)
+		}
+		expected := map[string]string{
+			"Projects":    badger.HealthOK,
+			"Templates":   badger.HealthUnavailable,
+			"StatusCache": badger.HealthUnavailable,
+		}
+		for name, status := range expected {
+			if result.Checks[name].Status != status {
+				t.Errorf("%s should be '%s' and not '%s'", name, status, result.Checks[name].Status)
+			}
+		}
+	})
+
+	t.Run("Ready", func(t *testing.T) {
+		os.Remove(brokenPage)
+		_, err := badgerBadger.Reload()
+		if err != nil {
+			t.Fatalf("Unable to reload: %s", err.Error())
+		}
+		badgerBadger.Refresh()
+
+		code, result := readiness()
+		if code != http.StatusOK {
+			t.Errorf("Code should be %d and not %d: %+v", http.StatusOK, code, result)
+		}
+		if result.Status != badger.HealthOK {
+			t.Errorf("Status should be '%s' and not '%s'", badger.HealthOK, result.Status)
+		}
+	})
+}
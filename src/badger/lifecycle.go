@@ -0,0 +1,180 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ShutdownTimeout is how long in-flight requests are given to complete
+// when the context passed to Start is cancelled
+const ShutdownTimeout = 10 * time.Second
+
+// Handler returns the HTTP handler with all of Badger's routes so that it
+// can be served by another server. The routes include the configured base
+// path. StartPolling refreshes the statuses and feeds the event streams
+// for an embedded handler.
+func (badger *Badger) Handler() http.Handler {
+	return badger.router
+}
+
+// StartPolling opens the event streams and refreshes the statuses in the
+// background without listening, for when Handler is served by another
+// server. Polling stops when ctx is cancelled or Shutdown is called,
+// Shutdown also closes the event streams.
+func (badger *Badger) StartPolling(ctx context.Context) error {
+	badger.lifecycleLock.Lock()
+	defer badger.lifecycleLock.Unlock()
+	if badger.stopPolling != nil {
+		return errors.New("Badger is already started")
+	}
+	badger.startPolling(ctx)
+	return nil
+}
+
+// startPolling does the work for StartPolling, the lifecycle lock must be
+// held
+func (badger *Badger) startPolling(ctx context.Context) {
+	badger.events.open()
+	pollContext, stopPolling := context.WithCancel(ctx)
+	badger.stopPolling = stopPolling
+	badger.polling.Add(1)
+	go func() {
+		defer badger.polling.Done()
+		badger.poll(pollContext)
+	}()
+}
+
+// Start listens on the configured address, refreshes the statuses in the
+// background and serves until ctx is cancelled or Shutdown is called.
+// Listening errors, ie. when the port is already in use, are returned
+// straight away. When ctx is cancelled the server is shut down with
+// ShutdownTimeout and Start returns once the shutdown completes. When
+// Shutdown is called, Start returns immediately and Shutdown waits for
+// the in-flight requests.
+func (badger *Badger) Start(ctx context.Context) error {
+	badger.lifecycleLock.Lock()
+	if badger.servers != nil || badger.stopPolling != nil {
+		badger.lifecycleLock.Unlock()
+		return errors.New("Badger is already started")
+	}
+
+	servers := []*http.Server{{
+		Addr:              badger.bindAddress,
+		Handler:           badger.router,
+		TLSConfig:         badger.tlsConfig,
+		ReadHeaderTimeout: 10 * time.Second,
+	}}
+	if badger.redirectAddress != "" {
+		servers = append(servers, &http.Server{
+			Addr:              badger.redirectAddress,
+			Handler:           http.HandlerFunc(badger.RedirectHandler),
+			ReadHeaderTimeout: 10 * time.Second,
+		})
+	}
+	listeners := make([]net.Listener, 0, len(servers))
+	for _, server := range servers {
+		listener, err := net.Listen("tcp", server.Addr)
+		if err != nil {
+			for _, opened := range listeners {
+				opened.Close()
+			}
+			badger.lifecycleLock.Unlock()
+			return errors.New("Unable to listen on " + server.Addr + ": " + err.Error())
+		}
+		listeners = append(listeners, listener)
+	}
+	badger.servers = servers
+	// Cancelling ctx shuts down the servers and stops polling below
+	badger.startPolling(context.Background())
+	badger.lifecycleLock.Unlock()
+
+	serveErrors := make(chan error, len(servers))
+	for i, server := range servers {
+		go func(server *http.Server, listener net.Listener) {
+			if server.TLSConfig != nil {
+				// The certificate comes from TLSConfig.GetCertificate
+				serveErrors <- server.ServeTLS(listener, "", "")
+			} else {
+				serveErrors <- server.Serve(listener)
+			}
+		}(server, listeners[i])
+		badger.log.Info("Listening on %s", server.Addr)
+	}
+
+	select {
+	case <-ctx.Done():
+		shutdownContext, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+		defer cancel()
+		return badger.Shutdown(shutdownContext)
+	case err := <-serveErrors:
+		if err == http.ErrServerClosed {
+			return nil
+		}
+		badger.log.Error("Server failed: %s", err.Error())
+		badger.Shutdown(context.Background())
+		return err
+	}
+}
+
+// Shutdown stops accepting connections, stops refreshing the statuses,
+// closes the event streams and waits for the in-flight requests, ie. badge
+// renders, and refreshes to complete or ctx to be done
+func (badger *Badger) Shutdown(ctx context.Context) error {
+	badger.lifecycleLock.Lock()
+	servers := badger.servers
+	stopPolling := badger.stopPolling
+	badger.servers = nil
+	badger.stopPolling = nil
+	badger.lifecycleLock.Unlock()
+
+	if stopPolling == nil {
+		return nil
+	}
+	badger.log.Info("Shutting down...")
+	stopPolling()
+	// Event streams never go idle, so they are closed before the servers
+	// wait for the in-flight requests
+	badger.events.close()
+	var shutdownErr error
+	for _, server := range servers {
+		err := server.Shutdown(ctx)
+		if err != nil && shutdownErr == nil {
+			shutdownErr = err
+		}
+	}
+
+	polled := make(chan struct{})
+	go func() {
+		badger.polling.Wait()
+		close(polled)
+	}()
+	select {
+	case <-polled:
+	case <-ctx.Done():
+		if shutdownErr == nil {
+			shutdownErr = ctx.Err()
+		}
+	}
+	if shutdownErr != nil {
+		badger.log.Warning("Shutdown did not complete: %s", shutdownErr.Error())
+		return shutdownErr
+	}
+	badger.log.Info("Shutdown complete")
+	return nil
+}
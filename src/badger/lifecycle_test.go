@@ -0,0 +1,227 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	badger "."
+)
+
+// freePort returns a port that is free to listen on
+func freePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// newTestBadger creates a Badger on port with a single project that fetches
+// its status from statusURL
+func newTestBadger(t *testing.T, dir string, statusURL string, port int) *badger.Badger {
+	writeProjectFile(t, dir, "sample.bbproj", `{
+    "Name": "Sample",
+    "Statuses": [{"Provider": "AppVeyor", "Url": "`+statusURL+`"}]
+}`)
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: port},
+		Fetch:        badger.FetchConfig{Retries: -1},
+		ProjectsPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+	return badgerBadger
+}
+
+func TestStartPortInUse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	badgerBadger := newTestBadger(t, dir, "http://127.0.0.1:1", listener.Addr().(*net.TCPAddr).Port)
+	err = badgerBadger.Start(context.Background())
+	if err == nil {
+		t.Errorf("Start should fail when the port is in use")
+	}
+}
+
+func TestStartShutdown(t *testing.T) {
+	var arrived int32
+	release := make(chan struct{})
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&arrived, 1)
+		<-release
+		w.Write([]byte(appVeyorStatusJSON))
+	}))
+	defer provider.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	port := freePort(t)
+	badgerBadger := newTestBadger(t, dir, provider.URL, port)
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", port)
+	// Spare keep-alive connections would hold up the shutdown
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+	started := make(chan error, 1)
+	go func() {
+		started <- badgerBadger.Start(context.Background())
+	}()
+	for i := 0; ; i++ {
+		response, err := client.Get(baseURL + "/healthz")
+		if err == nil {
+			response.Body.Close()
+			break
+		}
+		if i == 50 {
+			t.Fatalf("Badger did not start: %s", err.Error())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	// The status request blocks at the provider behind the first refresh
+	statusCode := make(chan int, 1)
+	go func() {
+		response, err := client.Get(baseURL + "/sample/status")
+		if err != nil {
+			statusCode <- 0
+			return
+		}
+		response.Body.Close()
+		statusCode <- response.StatusCode
+	}()
+	for atomic.LoadInt32(&arrived) < 2 {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdown <- badgerBadger.Shutdown(ctx)
+	}()
+	select {
+	case <-shutdown:
+		t.Fatalf("Shutdown should wait for the in-flight request")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+
+	if code := <-statusCode; code != http.StatusOK {
+		t.Errorf("The in-flight request should complete with %d and not %d", http.StatusOK, code)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown should complete: %s", err.Error())
+	}
+	if err := <-started; err != nil {
+		t.Errorf("Start should return without an error after a shutdown: %s", err.Error())
+	}
+	if _, err := client.Get(baseURL + "/healthz"); err == nil {
+		t.Errorf("Badger should not accept requests after a shutdown")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	badgerBadger := newTestBadger(t, dir, "http://127.0.0.1:1", 8000)
+
+	// Embedded in another server
+	server := httptest.NewServer(badgerBadger.Handler())
+	defer server.Close()
+	response, err := http.Get(server.URL + "/healthz")
+	if err != nil {
+		t.Fatalf("Unable to request health: %s", err.Error())
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		t.Errorf("Code should be %d and not %d", http.StatusOK, response.StatusCode)
+	}
+}
+
+func TestHandlerPolling(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(appVeyorStatusJSON))
+	}))
+	defer provider.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	badgerBadger := newTestBadger(t, dir, provider.URL, 8000)
+
+	// Embedded in another server, without Start
+	server := httptest.NewServer(badgerBadger.Handler())
+	defer server.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	err = badgerBadger.StartPolling(ctx)
+	if err != nil {
+		t.Fatalf("Unable to start polling: %s", err.Error())
+	}
+	defer badgerBadger.Shutdown(context.Background())
+	if badgerBadger.StartPolling(ctx) == nil {
+		t.Errorf("StartPolling should fail when Badger is already polling")
+	}
+
+	for i := 0; ; i++ {
+		response, err := http.Get(server.URL + "/readyz")
+		if err != nil {
+			t.Fatalf("Unable to request readiness: %s", err.Error())
+		}
+		response.Body.Close()
+		if response.StatusCode == http.StatusOK {
+			break
+		}
+		if i == 50 {
+			t.Fatalf("The statuses should be refreshed, readiness is %d", response.StatusCode)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	response, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatalf("Unable to request the root page: %s", err.Error())
+	}
+	body, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if strings.Contains(string(body), "Waiting for the first refresh") {
+		t.Errorf("Root page should show the refreshed statuses:\n%s", body)
+	}
+}
@@ -0,0 +1,186 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/donovansolms/lumberjack"
+	logging "github.com/op/go-logging"
+)
+
+const (
+	// LogEncodingText writes log lines using the go-logging Format
+	LogEncodingText = "text"
+	// LogEncodingJSON writes every log line as a JSON object
+	LogEncodingJSON = "json"
+
+	// accessLogModule is the logger module for the access log
+	accessLogModule = "Access"
+	// defaultLogFormat is the text format when none is configured
+	defaultLogFormat = "%{time:2006-01-02 15:04:05.000} %{level:.4s} %{shortfunc} > %{message}"
+	// defaultLogPath is the log file when none is configured
+	defaultLogPath = "logs/badger.log"
+	// defaultLogMaxBackups is the number of rotated log files kept
+	defaultLogMaxBackups = 3
+	// defaultLogMaxAgeDays is how long rotated log files are kept
+	defaultLogMaxAgeDays = 30
+)
+
+// jsonLogEntry is a single line of the JSON log encoding
+type jsonLogEntry struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Module  string `json:"module"`
+	Caller  string `json:"caller,omitempty"`
+	Message string `json:"message"`
+}
+
+// jsonFormatter formats log records as JSON objects
+type jsonFormatter struct{}
+
+// Format implements logging.Formatter
+func (formatter jsonFormatter) Format(calldepth int, record *logging.Record, output io.Writer) error {
+	entry := jsonLogEntry{
+		Time:    record.Time.UTC().Format(time.RFC3339Nano),
+		Level:   record.Level.String(),
+		Module:  record.Module,
+		Message: record.Message(),
+	}
+	if _, file, line, ok := runtime.Caller(calldepth + 1); ok {
+		entry.Caller = fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = output.Write(data)
+	return err
+}
+
+// newLogFormatter creates the formatter for a log output
+func newLogFormatter(output LogOutputConfig) (logging.Formatter, error) {
+	switch strings.ToLower(output.Encoding) {
+	case "", LogEncodingText:
+		format := output.Format
+		if format == "" {
+			format = defaultLogFormat
+		}
+		return logging.NewStringFormatter(format)
+	case LogEncodingJSON:
+		return jsonFormatter{}, nil
+	}
+	return nil, errors.New("Unknown log encoding '" + output.Encoding + "'")
+}
+
+// newLogFile creates the rotating log file for the file output
+func newLogFile(output LogOutputConfig) *lumberjack.Logger {
+	logFile := &lumberjack.Logger{
+		Filename:        output.Path,
+		MaxSize:         int(output.RotateSizeMB),
+		MaxBackups:      output.MaxBackups,
+		MaxAge:          output.MaxAgeDays,
+		CompressBackups: output.Compress,
+	}
+	if logFile.Filename == "" {
+		logFile.Filename = defaultLogPath
+	}
+	// Zero keeps the defaults, negative keeps everything
+	if logFile.MaxBackups == 0 {
+		logFile.MaxBackups = defaultLogMaxBackups
+	} else if logFile.MaxBackups < 0 {
+		logFile.MaxBackups = 0
+	}
+	if logFile.MaxAge == 0 {
+		logFile.MaxAge = defaultLogMaxAgeDays
+	} else if logFile.MaxAge < 0 {
+		logFile.MaxAge = 0
+	}
+	return logFile
+}
+
+// NewLog creates a new instance of the logger
+func NewLog(logConfig LogConfig) (*logging.Logger, error) {
+
+	// Collection of logging backends
+	backendFormatters := make([]logging.Backend, 0)
+	addBackend := func(output LogOutputConfig, backend logging.Backend) error {
+		format, err := newLogFormatter(output)
+		if err != nil {
+			return err
+		}
+		backendFormatter := logging.NewBackendFormatter(backend, format)
+		backendLeveled := logging.AddModuleLevel(backendFormatter)
+		backendLeveled.SetLevel(logging.DEBUG, "")
+		backendFormatters = append(backendFormatters, backendLeveled)
+		return nil
+	}
+
+	// If Console logging is enabled
+	if logConfig.Console.Enabled == true {
+		backend := logging.NewLogBackend(redactingWriter{os.Stderr}, "", 0)
+		err := addBackend(logConfig.Console, backend)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if logConfig.File.Enabled == true {
+		backend := logging.NewLogBackend(redactingWriter{newLogFile(logConfig.File)}, "", 0)
+		err := addBackend(logConfig.File, backend)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if logConfig.Syslog.Enabled == true {
+		backend, err := newSyslogBackend(logConfig.Syslog)
+		if err != nil {
+			return nil, errors.New("Unable to connect to syslog: " + err.Error())
+		}
+		err = addBackend(logConfig.Syslog, backend)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logging.SetBackend(backendFormatters...)
+
+	switch logConfig.Level {
+	case "DEBUG":
+		logging.SetLevel(logging.DEBUG, "")
+	case "INFO":
+		logging.SetLevel(logging.INFO, "")
+	case "WARNING":
+		logging.SetLevel(logging.WARNING, "")
+	case "ERROR":
+		logging.SetLevel(logging.ERROR, "")
+	case "CRITICAL":
+		logging.SetLevel(logging.CRITICAL, "")
+	}
+	// The access log is enabled separately from the log level
+	if logConfig.AccessLog {
+		logging.SetLevel(logging.INFO, accessLogModule)
+	}
+
+	log := logging.MustGetLogger("Badger")
+
+	return log, nil
+}
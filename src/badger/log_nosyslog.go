@@ -0,0 +1,28 @@
+//go:build windows || plan9
+// +build windows plan9
+
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"errors"
+
+	logging "github.com/op/go-logging"
+)
+
+// newSyslogBackend is not available on this platform
+func newSyslogBackend(output LogOutputConfig) (logging.Backend, error) {
+	return nil, errors.New("Syslog is not supported on this platform")
+}
@@ -0,0 +1,82 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"errors"
+	"log/syslog"
+	"strings"
+
+	logging "github.com/op/go-logging"
+)
+
+// syslogFacilities maps the configurable facility names
+var syslogFacilities = map[string]syslog.Priority{
+	"":       syslog.LOG_USER,
+	"user":   syslog.LOG_USER,
+	"daemon": syslog.LOG_DAEMON,
+	"local0": syslog.LOG_LOCAL0,
+	"local1": syslog.LOG_LOCAL1,
+	"local2": syslog.LOG_LOCAL2,
+	"local3": syslog.LOG_LOCAL3,
+	"local4": syslog.LOG_LOCAL4,
+	"local5": syslog.LOG_LOCAL5,
+	"local6": syslog.LOG_LOCAL6,
+	"local7": syslog.LOG_LOCAL7,
+}
+
+// syslogBackend writes redacted log records to syslog with the
+// matching severity
+type syslogBackend struct {
+	writer *syslog.Writer
+}
+
+// newSyslogBackend connects to the local syslog daemon, or the remote one
+// at Address when set
+func newSyslogBackend(output LogOutputConfig) (logging.Backend, error) {
+	facility, ok := syslogFacilities[strings.ToLower(output.Facility)]
+	if !ok {
+		return nil, errors.New("Unknown syslog facility '" + output.Facility + "'")
+	}
+	tag := output.Tag
+	if tag == "" {
+		tag = "badger"
+	}
+	writer, err := syslog.Dial(output.Network, output.Address, facility|syslog.LOG_INFO, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogBackend{writer: writer}, nil
+}
+
+// Log implements logging.Backend
+func (backend *syslogBackend) Log(level logging.Level, calldepth int, record *logging.Record) error {
+	line := Redact(record.Formatted(calldepth + 1))
+	switch level {
+	case logging.CRITICAL:
+		return backend.writer.Crit(line)
+	case logging.ERROR:
+		return backend.writer.Err(line)
+	case logging.WARNING:
+		return backend.writer.Warning(line)
+	case logging.NOTICE:
+		return backend.writer.Notice(line)
+	case logging.INFO:
+		return backend.writer.Info(line)
+	}
+	return backend.writer.Debug(line)
+}
@@ -0,0 +1,81 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	badger "."
+)
+
+func TestNewLogJSONFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	logPath := filepath.Join(dir, "nested", "badger.log")
+
+	log, err := badger.NewLog(badger.LogConfig{
+		Level: "INFO",
+		File: badger.LogOutputConfig{
+			Enabled:  true,
+			Encoding: badger.LogEncodingJSON,
+			Path:     logPath,
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unable to create log: %s", err.Error())
+	}
+	log.Debug("Not logged")
+	log.Warning("Project '%s' loaded", "sample")
+
+	contents, err := ioutil.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Log file should be created at the configured path: %s", err.Error())
+	}
+	lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 log line and not %d: %s", len(lines), contents)
+	}
+	var entry map[string]string
+	err = json.Unmarshal([]byte(lines[0]), &entry)
+	if err != nil {
+		t.Fatalf("Log line should be JSON: %s", err.Error())
+	}
+	expected := map[string]string{
+		"level":   "WARNING",
+		"module":  "Badger",
+		"message": "Project 'sample' loaded",
+	}
+	for field, value := range expected {
+		if entry[field] != value {
+			t.Errorf("%s should be '%s' and not '%s'", field, value, entry[field])
+		}
+	}
+}
+
+func TestNewLogUnknownEncoding(t *testing.T) {
+	_, err := badger.NewLog(badger.LogConfig{
+		Console: badger.LogOutputConfig{Enabled: true, Encoding: "xml"},
+	})
+	if err == nil {
+		t.Errorf("An unknown encoding should fail")
+	}
+}
@@ -0,0 +1,371 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"./parsers"
+)
+
+const (
+	// MetricsContentType is the Prometheus text exposition format
+	MetricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+	// defaultMetricsPath is the path of the metrics when none is configured
+	defaultMetricsPath = "/metrics"
+
+	metricKindCounter   = "counter"
+	metricKindGauge     = "gauge"
+	metricKindHistogram = "histogram"
+)
+
+const (
+	metricHTTPRequests        = "badger_http_requests_total"
+	metricHTTPRequestDuration = "badger_http_request_duration_seconds"
+	metricBadgeRenderDuration = "badger_badge_render_duration_seconds"
+	metricFetches             = "badger_provider_fetches_total"
+	metricFetchErrors         = "badger_provider_fetch_errors_total"
+	metricFetchDuration       = "badger_provider_fetch_duration_seconds"
+	metricProviderStatus      = "badger_provider_status"
+	metricProjectStatus       = "badger_project_status"
+	metricCacheRequests       = "badger_cache_requests_total"
+	metricCircuitState        = "badger_circuit_state"
+	metricEventSubscribers    = "badger_event_subscribers"
+	metricLastGoodResults     = "badger_last_good_results"
+)
+
+// defaultBuckets are the histogram buckets in seconds, the same as the
+// Prometheus client defaults
+var defaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// metricSeries is a single labelled value of a metric
+type metricSeries struct {
+	labelValues []string
+	value       float64
+	// counts, sum and count are only used by histograms
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// metricFamily is a metric with all its labelled series
+type metricFamily struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	series     map[string]*metricSeries
+}
+
+// Metrics keeps the metrics exposed in the Prometheus text format. A nil
+// *Metrics can be used and records nothing.
+type Metrics struct {
+	sync.Mutex
+	families map[string]*metricFamily
+}
+
+// NewMetrics creates the metrics registry with all of Badger's metrics
+func NewMetrics() *Metrics {
+	metrics := &Metrics{
+		families: make(map[string]*metricFamily),
+	}
+	metrics.register(metricKindCounter, metricHTTPRequests,
+		"HTTP requests by route, method and status code.", "route", "method", "code")
+	metrics.register(metricKindHistogram, metricHTTPRequestDuration,
+		"HTTP request latency by route.", "route")
+	metrics.register(metricKindHistogram, metricBadgeRenderDuration,
+		"Time taken to render a project badge, including fetching its statuses.", "project")
+	metrics.register(metricKindCounter, metricFetches,
+		"Status fetches by provider.", "provider")
+	metrics.register(metricKindCounter, metricFetchErrors,
+		"Failed status fetches by provider and reason.", "provider", "reason")
+	metrics.register(metricKindHistogram, metricFetchDuration,
+		"Status fetch latency by provider, including retries.", "provider")
+	metrics.register(metricKindGauge, metricProviderStatus,
+		"Current provider status per project, 1 for passing, 0 for failing and -1 for unknown.", "project", "provider")
+	metrics.register(metricKindGauge, metricProjectStatus,
+		"Current overall project status, 1 for passing and 0 for failing.", "project")
+	metrics.register(metricKindCounter, metricCacheRequests,
+		"Status cache lookups by cache and result, the hit ratio is hit / (hit + miss).", "cache", "result")
+	metrics.register(metricKindGauge, metricCircuitState,
+		"Circuit breaker state per provider endpoint, 0 for closed, 1 for half-open and 2 for open.", "endpoint")
+	metrics.register(metricKindGauge, metricEventSubscribers,
+		"Connected event stream clients.")
+	metrics.register(metricKindGauge, metricLastGoodResults,
+		"Last good provider results kept to serve while a provider is unavailable.")
+	return metrics
+}
+
+// register adds a metric family
+func (metrics *Metrics) register(kind string, name string, help string, labelNames ...string) {
+	metrics.families[name] = &metricFamily{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		series:     make(map[string]*metricSeries),
+	}
+}
+
+// seriesFor returns the series of the named metric with the label values,
+// the lock must be held
+func (metrics *Metrics) seriesFor(name string, labelValues []string) (*metricFamily, *metricSeries) {
+	family, ok := metrics.families[name]
+	if !ok || len(labelValues) != len(family.labelNames) {
+		panic("Invalid metric " + name)
+	}
+	key := strings.Join(labelValues, "\xff")
+	series, ok := family.series[key]
+	if !ok {
+		series = &metricSeries{
+			labelValues: append([]string(nil), labelValues...),
+		}
+		if family.kind == metricKindHistogram {
+			series.counts = make([]uint64, len(defaultBuckets))
+		}
+		family.series[key] = series
+	}
+	return family, series
+}
+
+// add increments a counter or gauge
+func (metrics *Metrics) add(name string, value float64, labelValues ...string) {
+	if metrics == nil {
+		return
+	}
+	metrics.Lock()
+	defer metrics.Unlock()
+	_, series := metrics.seriesFor(name, labelValues)
+	series.value += value
+}
+
+// set sets a gauge
+func (metrics *Metrics) set(name string, value float64, labelValues ...string) {
+	if metrics == nil {
+		return
+	}
+	metrics.Lock()
+	defer metrics.Unlock()
+	_, series := metrics.seriesFor(name, labelValues)
+	series.value = value
+}
+
+// observe adds a duration to a histogram
+func (metrics *Metrics) observe(name string, duration time.Duration, labelValues ...string) {
+	if metrics == nil {
+		return
+	}
+	metrics.Lock()
+	defer metrics.Unlock()
+	_, series := metrics.seriesFor(name, labelValues)
+	seconds := duration.Seconds()
+	for i, bucket := range defaultBuckets {
+		if seconds <= bucket {
+			series.counts[i]++
+		}
+	}
+	series.sum += seconds
+	series.count++
+}
+
+// deleteMatching removes all the series of the named metric that have
+// value for the label
+func (metrics *Metrics) deleteMatching(name string, label string, value string) {
+	if metrics == nil {
+		return
+	}
+	metrics.Lock()
+	defer metrics.Unlock()
+	family := metrics.families[name]
+	for i, labelName := range family.labelNames {
+		if labelName != label {
+			continue
+		}
+		for key, series := range family.series {
+			if series.labelValues[i] == value {
+				delete(family.series, key)
+			}
+		}
+	}
+}
+
+// WriteTo writes all the metrics in the Prometheus text format
+func (metrics *Metrics) WriteTo(w io.Writer) (int64, error) {
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	counter := &countingWriter{writer: w}
+	buffer := bufio.NewWriter(counter)
+	names := make([]string, 0, len(metrics.families))
+	for name := range metrics.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		family := metrics.families[name]
+		fmt.Fprintf(buffer, "# HELP %s %s\n", family.name, escapeMetricHelp(family.help))
+		fmt.Fprintf(buffer, "# TYPE %s %s\n", family.name, family.kind)
+		keys := make([]string, 0, len(family.series))
+		for key := range family.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			series := family.series[key]
+			labels := formatMetricLabels(family.labelNames, series.labelValues)
+			if family.kind != metricKindHistogram {
+				fmt.Fprintf(buffer, "%s%s %s\n", family.name, wrapMetricLabels(labels), formatMetricValue(series.value))
+				continue
+			}
+			for i, bucket := range defaultBuckets {
+				bucketLabels := appendMetricLabel(labels, "le", formatMetricValue(bucket))
+				fmt.Fprintf(buffer, "%s_bucket%s %d\n", family.name, wrapMetricLabels(bucketLabels), series.counts[i])
+			}
+			fmt.Fprintf(buffer, "%s_bucket%s %d\n", family.name, wrapMetricLabels(appendMetricLabel(labels, "le", "+Inf")), series.count)
+			fmt.Fprintf(buffer, "%s_sum%s %s\n", family.name, wrapMetricLabels(labels), formatMetricValue(series.sum))
+			fmt.Fprintf(buffer, "%s_count%s %d\n", family.name, wrapMetricLabels(labels), series.count)
+		}
+	}
+	err := buffer.Flush()
+	return counter.written, err
+}
+
+// countingWriter counts the bytes written to writer
+type countingWriter struct {
+	writer  io.Writer
+	written int64
+}
+
+// Write implements io.Writer
+func (counter *countingWriter) Write(p []byte) (int, error) {
+	n, err := counter.writer.Write(p)
+	counter.written += int64(n)
+	return n, err
+}
+
+// formatMetricLabels formats label pairs without the surrounding braces
+func formatMetricLabels(names []string, values []string) string {
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeMetricLabel(values[i]) + `"`
+	}
+	return strings.Join(pairs, ",")
+}
+
+// appendMetricLabel adds a label pair to formatted labels
+func appendMetricLabel(labels string, name string, value string) string {
+	if labels != "" {
+		labels += ","
+	}
+	return labels + name + `="` + escapeMetricLabel(value) + `"`
+}
+
+// wrapMetricLabels adds the braces around formatted labels if there are any
+func wrapMetricLabels(labels string) string {
+	if labels == "" {
+		return ""
+	}
+	return "{" + labels + "}"
+}
+
+// formatMetricValue formats a sample value
+func formatMetricValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// escapeMetricLabel escapes a label value
+func escapeMetricLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// escapeMetricHelp escapes a help text
+func escapeMetricHelp(help string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(help)
+}
+
+// statusValue maps a provider status to its gauge value
+func statusValue(status string) float64 {
+	switch status {
+	case parsers.ProviderStatusSuccess:
+		return 1
+	case parsers.ProviderStatusFailed:
+		return 0
+	}
+	return -1
+}
+
+// circuitValue maps a circuit state to its gauge value
+func circuitValue(state string) float64 {
+	switch state {
+	case CircuitHalfOpen:
+		return 1
+	case CircuitOpen:
+		return 2
+	}
+	return 0
+}
+
+// recordStatuses updates the status gauges of a project
+func (metrics *Metrics) recordStatuses(project string, overall parsers.ProviderResult, providers map[string]parsers.ProviderResult) {
+	if metrics == nil {
+		return
+	}
+	metrics.set(metricProjectStatus, statusValue(overall.Status), project)
+	for provider, result := range providers {
+		metrics.set(metricProviderStatus, statusValue(result.Status), project, provider)
+	}
+}
+
+// forgetProject removes the status gauges of a project that is no longer
+// loaded
+func (metrics *Metrics) forgetProject(project string) {
+	metrics.deleteMatching(metricProjectStatus, "project", project)
+	metrics.deleteMatching(metricProviderStatus, "project", project)
+	metrics.deleteMatching(metricBadgeRenderDuration, "project", project)
+}
+
+// MetricsHandler serves the metrics in the Prometheus text format
+func (badger *Badger) MetricsHandler(w http.ResponseWriter, r *http.Request) {
+	// The circuit states, subscribers and last good results are read on
+	// every scrape
+	if badger.Fetcher != nil {
+		for endpoint, state := range badger.Fetcher.breaker.states() {
+			badger.Metrics.set(metricCircuitState, circuitValue(state), endpoint)
+		}
+		badger.Metrics.set(metricLastGoodResults, float64(badger.Fetcher.limiter.count()))
+	}
+	badger.Metrics.set(metricEventSubscribers, float64(badger.events.count()))
+	w.Header().Set("Content-Type", MetricsContentType)
+	_, err := badger.Metrics.WriteTo(w)
+	if err != nil {
+		badger.log.Warning("Unable to write metrics: %s", err.Error())
+	}
+}
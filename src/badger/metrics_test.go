@@ -0,0 +1,122 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	badger "."
+)
+
+func TestMetricsFetchStatus(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(appVeyorStatusJSON))
+	}))
+	defer server.Close()
+	status := badger.StatusConfig{
+		Provider: "AppVeyor",
+		URL:      server.URL,
+	}
+	fetcher := newFetcher(t, badger.FetchConfig{Retries: -1})
+	fetcher.Metrics = badger.NewMetrics()
+
+	fetcher.FetchStatus(status)
+	fetcher.FetchStatus(status)
+
+	buffer := new(bytes.Buffer)
+	_, err := fetcher.Metrics.WriteTo(buffer)
+	if err != nil {
+		t.Fatalf("Unable to write metrics: %s", err.Error())
+	}
+	output := buffer.String()
+	expected := []string{
+		"# TYPE badger_provider_fetches_total counter\n",
+		"badger_provider_fetches_total{provider=\"appveyor\"} 2\n",
+		"badger_provider_fetch_errors_total{provider=\"appveyor\",reason=\"rate_limited\"} 1\n",
+		"badger_provider_fetch_duration_seconds_bucket{provider=\"appveyor\",le=\"+Inf\"} 2\n",
+		"badger_provider_fetch_duration_seconds_count{provider=\"appveyor\"} 2\n",
+		"badger_cache_requests_total{cache=\"last_good\",result=\"hit\"} 1\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("Metrics should contain '%s', got:\n%s", strings.TrimSpace(line), output)
+		}
+	}
+}
+
+func TestMetricsNil(t *testing.T) {
+	server := newStatusServer(t, func(r *http.Request) {})
+	defer server.Close()
+
+	// A fetcher without metrics must still work
+	_, err := newFetcher(t, badger.FetchConfig{}).FetchStatus(badger.StatusConfig{
+		Provider: "AppVeyor",
+		URL:      server.URL,
+	})
+	if err != nil {
+		t.Errorf("Unable to fetch status: %s", err.Error())
+	}
+}
+
+func TestMetricsHTTPMethod(t *testing.T) {
+	server := newStatusServer(t, func(r *http.Request) {})
+	defer server.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeProjectFile(t, dir, "sample.bbproj", `{
+    "Name": "Sample",
+    "Statuses": [{"Provider": "AppVeyor", "Url": "`+server.URL+`"}]
+}`)
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: 8000},
+		ProjectsPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+	for _, method := range []string{"GET", "FOO", "X-RANDOM-1", "X-RANDOM-2"} {
+		badgerBadger.Handler().ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/missing", nil))
+	}
+
+	recorder := httptest.NewRecorder()
+	badgerBadger.MetricsHandler(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	output := recorder.Body.String()
+	expected := []string{
+		"badger_http_requests_total{route=\"/{project}\",method=\"GET\",code=\"404\"} 1\n",
+		"badger_http_requests_total{route=\"/{project}\",method=\"other\",code=\"404\"} 3\n",
+	}
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("Metrics should contain '%s', got:\n%s", strings.TrimSpace(line), output)
+		}
+	}
+	if strings.Contains(output, "FOO") {
+		t.Errorf("Metrics should not contain the raw method 'FOO':\n%s", output)
+	}
+}
@@ -15,6 +15,8 @@ package parsers
 
 import (
 	"encoding/json"
+	"errors"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -41,6 +43,17 @@ type AppveyorData struct {
 		Finished          time.Time `json:"finished"`
 		Created           time.Time `json:"created"`
 		Updated           time.Time `json:"updated"`
+		Jobs              []struct {
+			JobID            string    `json:"jobId"`
+			Name             string    `json:"name"`
+			AllowFailure     bool      `json:"allowFailure"`
+			Status           string    `json:"status"`
+			Started          time.Time `json:"started"`
+			Finished         time.Time `json:"finished"`
+			TestsCount       int       `json:"testsCount"`
+			PassedTestsCount int       `json:"passedTestsCount"`
+			FailedTestsCount int       `json:"failedTestsCount"`
+		} `json:"jobs"`
 	} `json:"build"`
 }
 
@@ -54,21 +67,63 @@ func (parser *AppveyorParser) Parse(raw []byte) (ProviderResult, error) {
 	if err != nil {
 		return result, err
 	}
+	return parser.parseData(result, data), nil
+}
 
-	switch strings.ToLower(data.Build.Status) {
-	case "success":
-		result.Status = ProviderStatusSuccess
-		result.IsSuccess = true
-	case "failed":
-		result.Status = ProviderStatusFailed
-	default:
-		result.Status = ProviderStatusUnknown
+// ParseBranch parses the json bytes into a provider result, the build must
+// be of branch. The branch is usually in the URL for AppVeyor, ie.
+// /api/projects/{account}/{project}/branch/{branch}.
+func (parser *AppveyorParser) ParseBranch(raw []byte, branch string) (ProviderResult, error) {
+	var result ProviderResult
+	result.ProperName = parser.Name()
+	result.Provider = "AppVeyor"
+	var data AppveyorData
+	err := json.Unmarshal(raw, &data)
+	if err != nil {
+		return result, err
+	}
+	if data.Build.Branch != "" && data.Build.Branch != branch {
+		return result, errors.New("No builds found for branch '" + branch + "' on AppVeyor")
 	}
+	return parser.parseData(result, data), nil
+}
+
+// parseData fills in the result from the build
+func (parser *AppveyorParser) parseData(result ProviderResult, data AppveyorData) ProviderResult {
 	result.BuildDateTime = data.Build.Finished
 	result.CommitMessage = data.Build.Message
 	result.CommitUser = data.Build.CommitterName
+	for i, job := range data.Build.Jobs {
+		result.TestsCount += job.TestsCount
+		result.TestsPassed += job.PassedTestsCount
+		result.TestsFailed += job.FailedTestsCount
+		name := job.Name
+		if name == "" {
+			name = "Job " + strconv.Itoa(i+1)
+		}
+		result.Jobs = append(result.Jobs, JobResult{
+			Name:         name,
+			Status:       appveyorStatus(job.Status),
+			Duration:     jobDuration(job.Started, job.Finished),
+			AllowFailure: job.AllowFailure,
+		})
+	}
+	result.Status = jobsStatus(result.Jobs, appveyorStatus(data.Build.Status))
+	result.IsSuccess = result.Status == ProviderStatusSuccess
+	result.Branch = data.Build.Branch
+	return result
+}
 
-	return result, nil
+// appveyorStatus converts the status of a build or job
+func appveyorStatus(status string) string {
+	switch strings.ToLower(status) {
+	case "success":
+		return ProviderStatusSuccess
+	case "failed":
+		return ProviderStatusFailed
+	default:
+		return ProviderStatusUnknown
+	}
 }
 
 // Name returns the Proper name of the provider for the parser
@@ -13,7 +13,12 @@
 
 package parsers_test
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	parsers "."
+)
 
 func TestAppveyorName(t *testing.T) {
 	expected := "AppVeyor"
@@ -52,6 +57,25 @@ func TestAppveyorParse(t *testing.T) {
 			t.Errorf("CommitMessage should be '%s' and not '%s'", "Clean up comments", parseResult.CommitMessage)
 		}
 	})
+
+	t.Run("Tests", func(t *testing.T) {
+		if parseResult.TestsCount != 18 || parseResult.TestsPassed != 18 || parseResult.TestsFailed != 0 {
+			t.Errorf("Tests should be 18/18/0 and not %d/%d/%d", parseResult.TestsCount, parseResult.TestsPassed, parseResult.TestsFailed)
+		}
+	})
+
+	t.Run("Jobs", func(t *testing.T) {
+		if len(parseResult.Jobs) != 1 {
+			t.Fatalf("Jobs should have %d jobs and not %d", 1, len(parseResult.Jobs))
+		}
+		job := parseResult.Jobs[0]
+		if job.Name != "Job 1" || job.Status != "Passing" || job.AllowFailure {
+			t.Errorf("Job should be 'Job 1' 'Passing' and not '%s' '%s'", job.Name, job.Status)
+		}
+		if job.Duration.Round(time.Second) != 49*time.Second {
+			t.Errorf("Duration should be '%s' and not '%s'", 49*time.Second, job.Duration)
+		}
+	})
 }
 
 func TestAppveyorParseInvalidJSON(t *testing.T) {
@@ -60,3 +84,35 @@ func TestAppveyorParseInvalidJSON(t *testing.T) {
 		t.Error("Parsing should have returned an error for invalid JSON")
 	}
 }
+
+func TestAppveyorParseBranch(t *testing.T) {
+	branchParser := appVeyorParser.(parsers.BranchParser)
+	parseResult, err := branchParser.ParseBranch([]byte(appVeyorJson), "master")
+	if err != nil {
+		t.Errorf("Unable to parse AppVeyor JSON for branch: %s", err.Error())
+	}
+	if parseResult.Branch != "master" {
+		t.Errorf("Branch should be '%s' and not '%s'", "master", parseResult.Branch)
+	}
+	_, err = branchParser.ParseBranch([]byte(appVeyorJson), "release-2.x")
+	if err == nil {
+		t.Error("Parsing should have returned an error for the build of another branch")
+	}
+}
+
+func TestAppveyorParseAllowFailure(t *testing.T) {
+	build := `{"build": {"status": "failed", "jobs": [
+        {"name": "Windows", "status": "success"},
+        {"name": "Nightly", "status": "failed", "allowFailure": true}
+    ]}}`
+	parseResult, err := appVeyorParser.Parse([]byte(build))
+	if err != nil {
+		t.Errorf("Unable to parse AppVeyor JSON: %s", err.Error())
+	}
+	if parseResult.Status != "Passing" || !parseResult.IsSuccess {
+		t.Errorf("Status should be '%s' and not '%s'", "Passing", parseResult.Status)
+	}
+	if len(parseResult.Jobs) != 2 || parseResult.Jobs[1].Name != "Nightly" || !parseResult.Jobs[1].AllowFailure {
+		t.Errorf("Jobs should list the job that is allowed to fail, got %v", parseResult.Jobs)
+	}
+}
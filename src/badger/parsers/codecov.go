@@ -0,0 +1,80 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package parsers
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// CodecovParser is the coverage parser for Codecov
+type CodecovParser struct {
+}
+
+// CodecovData is the JSON API structure for a Codecov repository. The v2
+// API reports the totals of the repository, the v1 API the totals of the
+// last commit.
+type CodecovData struct {
+	Totals *struct {
+		Coverage float64 `json:"coverage"`
+	} `json:"totals"`
+	UpdateStamp time.Time `json:"updatestamp"`
+	Commit      *struct {
+		Totals struct {
+			Coverage string `json:"c"`
+		} `json:"totals"`
+		Message   string                `json:"message"`
+		Author    struct{ Name string } `json:"author"`
+		Timestamp time.Time             `json:"timestamp"`
+	} `json:"commit"`
+}
+
+// Parse parses the json bytes into a provider result
+func (parser *CodecovParser) Parse(raw []byte) (ProviderResult, error) {
+	var result ProviderResult
+	result.ProperName = parser.Name()
+	result.Provider = "Codecov"
+	var data CodecovData
+	err := json.Unmarshal(raw, &data)
+	if err != nil {
+		return result, err
+	}
+
+	switch {
+	case data.Totals != nil:
+		result.Coverage = data.Totals.Coverage
+		result.BuildDateTime = data.UpdateStamp
+	case data.Commit != nil && data.Commit.Totals.Coverage != "":
+		coverage, err := strconv.ParseFloat(data.Commit.Totals.Coverage, 64)
+		if err != nil {
+			return result, errors.New("Invalid coverage for Codecov: " + data.Commit.Totals.Coverage)
+		}
+		result.Coverage = coverage
+		result.BuildDateTime = data.Commit.Timestamp
+		result.CommitMessage = data.Commit.Message
+		result.CommitUser = data.Commit.Author.Name
+	default:
+		return result, errors.New("No coverage found for Codecov")
+	}
+	result.Status = ProviderStatusSuccess
+	result.IsSuccess = true
+	return result, nil
+}
+
+// Name returns the Proper name of the provider for the parser
+func (parser *CodecovParser) Name() string {
+	return "Codecov"
+}
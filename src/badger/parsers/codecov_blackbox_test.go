@@ -0,0 +1,63 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package parsers_test
+
+import "testing"
+
+func TestCodecovName(t *testing.T) {
+	expected := "Codecov"
+	v := codecovParser.Name()
+	if v != expected {
+		t.Errorf("Codecov parser should set name to '%s' and not '%s'", expected, v)
+	}
+}
+
+func TestCodecovParse(t *testing.T) {
+	parseResult, err := codecovParser.Parse([]byte(codecovJson))
+	if err != nil {
+		t.Errorf("Unable to parse Codecov JSON: %s", err.Error())
+	}
+
+	t.Run("Status", func(t *testing.T) {
+		if parseResult.Status != "Passing" {
+			t.Errorf("Status should be '%s' and not '%s'", "Passing", parseResult.Status)
+		}
+	})
+
+	t.Run("Coverage", func(t *testing.T) {
+		if parseResult.Coverage != 87.5 {
+			t.Errorf("Coverage should be '%f' and not '%f'", 87.5, parseResult.Coverage)
+		}
+	})
+}
+
+func TestCodecovParseCommit(t *testing.T) {
+	parseResult, err := codecovParser.Parse([]byte(`{"commit": {"totals": {"c": "72.25"}, "message": "Add tests", "author": {"name": "Donovan Solms"}}}`))
+	if err != nil {
+		t.Errorf("Unable to parse Codecov JSON: %s", err.Error())
+	}
+	if parseResult.Coverage != 72.25 {
+		t.Errorf("Coverage should be '%f' and not '%f'", 72.25, parseResult.Coverage)
+	}
+	if parseResult.CommitMessage != "Add tests" {
+		t.Errorf("CommitMessage should be '%s' and not '%s'", "Add tests", parseResult.CommitMessage)
+	}
+}
+
+func TestCodecovParseNoCoverage(t *testing.T) {
+	_, err := codecovParser.Parse([]byte(`{"name": "ioRPC"}`))
+	if err == nil {
+		t.Error("Parsing should have returned an error without coverage")
+	}
+}
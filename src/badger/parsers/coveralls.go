@@ -0,0 +1,85 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package parsers
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// CoverallsParser is the coverage parser for Coveralls
+type CoverallsParser struct {
+}
+
+// CoverallsData is the JSON API structure for a Coveralls repository, ie.
+// https://coveralls.io/github/{owner}/{repo}.json
+type CoverallsData struct {
+	CoveredPercent *float64  `json:"covered_percent"`
+	CreatedAt      time.Time `json:"created_at"`
+	Branch         string    `json:"branch"`
+	CommitMessage  string    `json:"commit_message"`
+	CommitterName  string    `json:"committer_name"`
+}
+
+// Parse parses the json bytes into a provider result
+func (parser *CoverallsParser) Parse(raw []byte) (ProviderResult, error) {
+	var result ProviderResult
+	result.ProperName = parser.Name()
+	result.Provider = "Coveralls"
+	var data CoverallsData
+	err := json.Unmarshal(raw, &data)
+	if err != nil {
+		return result, err
+	}
+	return parser.parseData(result, data)
+}
+
+// ParseBranch parses the json bytes into a provider result, the coverage
+// must be of branch, ie. from /github/{owner}/{repo}.json?branch={branch}
+func (parser *CoverallsParser) ParseBranch(raw []byte, branch string) (ProviderResult, error) {
+	var result ProviderResult
+	result.ProperName = parser.Name()
+	result.Provider = "Coveralls"
+	var data CoverallsData
+	err := json.Unmarshal(raw, &data)
+	if err != nil {
+		return result, err
+	}
+	if data.Branch != "" && data.Branch != branch {
+		return result, errors.New("No coverage found for branch '" + branch + "' on Coveralls")
+	}
+	return parser.parseData(result, data)
+}
+
+// parseData fills in the result from the coverage
+func (parser *CoverallsParser) parseData(result ProviderResult, data CoverallsData) (ProviderResult, error) {
+	if data.CoveredPercent == nil {
+		return result, errors.New("No coverage found for Coveralls")
+	}
+
+	result.Status = ProviderStatusSuccess
+	result.IsSuccess = true
+	result.Coverage = *data.CoveredPercent
+	result.BuildDateTime = data.CreatedAt
+	result.CommitMessage = data.CommitMessage
+	result.CommitUser = data.CommitterName
+	result.Branch = data.Branch
+	return result, nil
+}
+
+// Name returns the Proper name of the provider for the parser
+func (parser *CoverallsParser) Name() string {
+	return "Coveralls"
+}
@@ -0,0 +1,63 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package parsers_test
+
+import "testing"
+
+func TestCoverallsName(t *testing.T) {
+	expected := "Coveralls"
+	v := coverallsParser.Name()
+	if v != expected {
+		t.Errorf("Coveralls parser should set name to '%s' and not '%s'", expected, v)
+	}
+}
+
+func TestCoverallsParse(t *testing.T) {
+	parseResult, err := coverallsParser.Parse([]byte(coverallsJson))
+	if err != nil {
+		t.Errorf("Unable to parse Coveralls JSON: %s", err.Error())
+	}
+
+	t.Run("Status", func(t *testing.T) {
+		if parseResult.Status != "Passing" {
+			t.Errorf("Status should be '%s' and not '%s'", "Passing", parseResult.Status)
+		}
+	})
+
+	t.Run("Coverage", func(t *testing.T) {
+		if parseResult.Coverage != 87.46 {
+			t.Errorf("Coverage should be '%f' and not '%f'", 87.46, parseResult.Coverage)
+		}
+	})
+
+	t.Run("CommitUser", func(t *testing.T) {
+		if parseResult.CommitUser != "Donovan Solms" {
+			t.Errorf("CommitUser should be '%s' and not '%s'", "Donovan Solms", parseResult.CommitUser)
+		}
+	})
+}
+
+func TestCoverallsParseNoCoverage(t *testing.T) {
+	_, err := coverallsParser.Parse([]byte(`{"branch": "master"}`))
+	if err == nil {
+		t.Error("Parsing should have returned an error without coverage")
+	}
+}
+
+func TestCoverallsParseInvalidJSON(t *testing.T) {
+	_, err := coverallsParser.Parse([]byte("{name:}"))
+	if err == nil {
+		t.Error("Parsing should have returned an error for invalid JSON")
+	}
+}
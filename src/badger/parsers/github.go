@@ -0,0 +1,73 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package parsers
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// GitHubParser is the release parser for GitHub
+type GitHubParser struct {
+}
+
+// GitHubRelease is the JSON API structure for a GitHub release
+type GitHubRelease struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Author      struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// Parse parses the json bytes of the latest release, or a list of
+// releases, into a provider result. Drafts and prereleases are skipped.
+func (parser *GitHubParser) Parse(raw []byte) (ProviderResult, error) {
+	var result ProviderResult
+	result.ProperName = parser.Name()
+	result.Provider = "GitHub"
+	var releases []GitHubRelease
+	err := json.Unmarshal(raw, &releases)
+	if err != nil {
+		// /releases/latest returns a single release
+		var release GitHubRelease
+		if json.Unmarshal(raw, &release) != nil {
+			return result, err
+		}
+		releases = []GitHubRelease{release}
+	}
+
+	for _, release := range releases {
+		if release.Draft || release.Prerelease || release.TagName == "" {
+			continue
+		}
+		result.Status = ProviderStatusSuccess
+		result.IsSuccess = true
+		result.Version = release.TagName
+		result.BuildDateTime = release.PublishedAt
+		result.CommitMessage = release.Name
+		result.CommitUser = release.Author.Login
+		return result, nil
+	}
+	return result, errors.New("No releases found for GitHub")
+}
+
+// Name returns the Proper name of the provider for the parser
+func (parser *GitHubParser) Name() string {
+	return "GitHub"
+}
@@ -0,0 +1,73 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package parsers_test
+
+import "testing"
+
+func TestGitHubName(t *testing.T) {
+	expected := "GitHub"
+	v := gitHubParser.Name()
+	if v != expected {
+		t.Errorf("GitHub parser should set name to '%s' and not '%s'", expected, v)
+	}
+}
+
+func TestGitHubParse(t *testing.T) {
+	parseResult, err := gitHubParser.Parse([]byte(gitHubJson))
+	if err != nil {
+		t.Errorf("Unable to parse GitHub JSON: %s", err.Error())
+	}
+
+	t.Run("Version", func(t *testing.T) {
+		if parseResult.Version != "v1.0.0" {
+			t.Errorf("Version should be '%s' and not '%s'", "v1.0.0", parseResult.Version)
+		}
+	})
+
+	t.Run("CommitMessage", func(t *testing.T) {
+		if parseResult.CommitMessage != "First release" {
+			t.Errorf("CommitMessage should be '%s' and not '%s'", "First release", parseResult.CommitMessage)
+		}
+	})
+}
+
+func TestGitHubParseLatest(t *testing.T) {
+	parseResult, err := gitHubParser.Parse([]byte(`{"tag_name": "v2.0.0", "name": "Latest"}`))
+	if err != nil {
+		t.Errorf("Unable to parse GitHub JSON: %s", err.Error())
+	}
+	if parseResult.Version != "v2.0.0" {
+		t.Errorf("Version should be '%s' and not '%s'", "v2.0.0", parseResult.Version)
+	}
+}
+
+func TestGitHubParsePrerelease(t *testing.T) {
+	parseResult, err := gitHubParser.Parse([]byte(`[
+    {"tag_name": "v2.0.0-rc.1", "name": "Release candidate", "prerelease": true},
+    {"tag_name": "v1.1.0", "name": "Stable"}
+]`))
+	if err != nil {
+		t.Errorf("Unable to parse GitHub JSON: %s", err.Error())
+	}
+	if parseResult.Version != "v1.1.0" {
+		t.Errorf("Version should be '%s' and not '%s'", "v1.1.0", parseResult.Version)
+	}
+}
+
+func TestGitHubParseNoReleases(t *testing.T) {
+	_, err := gitHubParser.Parse([]byte(`[]`))
+	if err == nil {
+		t.Error("Parsing should have returned an error without releases")
+	}
+}
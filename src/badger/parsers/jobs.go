@@ -0,0 +1,64 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package parsers
+
+import "time"
+
+// JobResult is the status of a job of a build, ie. one entry of a build
+// matrix
+type JobResult struct {
+	Name   string
+	Status string
+	// Zero while the job is running or if the provider doesn't report it
+	Duration time.Duration
+	// Set when the job may fail without failing the build, the status of
+	// these jobs is ignored
+	AllowFailure bool
+}
+
+// jobDuration returns the duration of a job, zero if it hasn't finished
+func jobDuration(started time.Time, finished time.Time) time.Duration {
+	if started.IsZero() || finished.IsZero() || finished.Before(started) {
+		return 0
+	}
+	return finished.Sub(started)
+}
+
+// jobsStatus computes the status of a build from its jobs. It fails if a
+// job fails and is unknown while a job has no result, jobs that are allowed
+// to fail are ignored. status is returned if there are no other jobs.
+func jobsStatus(jobs []JobResult, status string) string {
+	counted := false
+	unknown := false
+	for _, job := range jobs {
+		if job.AllowFailure {
+			continue
+		}
+		counted = true
+		switch job.Status {
+		case ProviderStatusFailed:
+			return ProviderStatusFailed
+		case ProviderStatusSuccess:
+		default:
+			unknown = true
+		}
+	}
+	if !counted {
+		return status
+	}
+	if unknown {
+		return ProviderStatusUnknown
+	}
+	return ProviderStatusSuccess
+}
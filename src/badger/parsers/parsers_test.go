@@ -22,13 +22,25 @@ import (
 
 var appVeyorParser parsers.Parser
 var travisCIParser parsers.Parser
+var coverallsParser parsers.Parser
+var codecovParser parsers.Parser
+var gitHubParser parsers.Parser
 var appVeyorJson string
 var travisCIJson string
+var coverallsJson string
+var codecovJson string
+var gitHubJson string
 
 func TestMain(m *testing.M) {
 	appVeyorParser = &parsers.AppveyorParser{}
 	travisCIParser = &parsers.TravisCIParser{}
+	coverallsParser = &parsers.CoverallsParser{}
+	codecovParser = &parsers.CodecovParser{}
+	gitHubParser = &parsers.GitHubParser{}
 	appVeyorJson = "{\"project\": {\"projectId\": 220088,\"accountId\": 44354,\"accountName\": \"donovansolms\",\"builds\": [],\"name\": \"ioRPC\",\"slug\": \"iorpc\",\"repositoryType\": \"gitHub\",\"repositoryScm\": \"git\",\"repositoryName\": \"ProjectLimitless/ioRPC\",\"repositoryBranch\": \"master\",\"isPrivate\": false,\"skipBranchesWithoutAppveyorYml\": false,\"enableSecureVariablesInPullRequests\": false,\"enableSecureVariablesInPullRequestsFromSameRepo\": false,\"enableDeploymentInPullRequests\": false,\"rollingBuilds\": false,\"alwaysBuildClosedPullRequests\": false,\"nuGetFeed\": {\"id\": \"iorpc-f1rq241u6kft\",\"name\": \"Project ioRPC\",\"publishingEnabled\": false,\"created\": \"2016-07-29T13:22:10.5478665+00:00\"},\"securityDescriptor\": {\"accessRightDefinitions\": [{\"name\": \"View\",\"description\": \"View\"},{\"name\": \"RunBuild\",\"description\": \"Run build\"},{\"name\": \"Update\",\"description\": \"Update settings\"},{\"name\": \"Delete\",\"description\": \"Delete project\"}],\"roleAces\": [{\"roleId\": 76364,\"name\": \"Administrator\",\"isAdmin\": true,\"accessRights\": [{\"name\": \"View\",\"allowed\": true},{\"name\": \"RunBuild\",\"allowed\": true},{\"name\": \"Update\",\"allowed\": true},{\"name\": \"Delete\",\"allowed\": true}]},{\"roleId\": 76365,\"name\": \"User\",\"isAdmin\": false,\"accessRights\": [{\"name\": \"View\"},{\"name\": \"RunBuild\"},{\"name\": \"Update\"},{\"name\": \"Delete\"}]}]},\"created\": \"2016-07-29T13:22:07.938561+00:00\",\"updated\": \"2016-08-25T09:44:16.0887202+00:00\"},\"build\": {\"buildId\": 4654641,\"jobs\": [{\"jobId\": \"x3k55m2x16hfi7c1\",\"name\": \"\",\"allowFailure\": false,\"messagesCount\": 0,\"compilationMessagesCount\": 17,\"compilationErrorsCount\": 0,\"compilationWarningsCount\": 17,\"testsCount\": 18,\"passedTestsCount\": 18,\"failedTestsCount\": 0,\"artifactsCount\": 1,\"status\": \"success\",\"started\": \"2016-08-25T11:03:34.1692307+00:00\",\"finished\": \"2016-08-25T11:04:23.3755601+00:00\",\"created\": \"2016-08-25T11:03:25.2931592+00:00\",\"updated\": \"2016-08-25T11:04:23.3755601+00:00\"}],\"buildNumber\": 32,\"version\": \"1.0.0.32\",\"message\": \"Clean up comments\",\"branch\": \"master\",\"isTag\": false,\"commitId\": \"48e98e50dbdc0a94a899f8c39baeb1f713183870\",\"authorName\": \"Donovan Solms\",\"authorUsername\": \"donovansolms\",\"committerName\": \"Donovan Solms\",\"committerUsername\": \"donovansolms\",\"committed\": \"2016-08-25T11:03:14+00:00\",\"messages\": [],\"status\": \"success\",\"started\": \"2016-08-25T11:03:34.184853+00:00\",\"finished\": \"2016-08-25T11:04:23.5318057+00:00\",\"created\": \"2016-08-25T11:03:22.808839+00:00\",\"updated\": \"2016-08-25T11:04:23.5318057+00:00\"}}"
 	travisCIJson = "[{\"id\":155018968,\"repository_id\":9577945,\"number\":\"32\",\"state\":\"finished\",\"result\":0,\"started_at\":\"2016-08-25T11:05:46Z\",\"finished_at\":\"2016-08-25T11:07:04Z\",\"duration\":78,\"commit\":\"48e98e50dbdc0a94a899f8c39baeb1f713183870\",\"branch\":\"master\",\"message\":\"Clean up comments\",\"event_type\":\"push\"},{\"id\":155014619,\"repository_id\":9577945,\"number\":\"31\",\"state\":\"finished\",\"result\":0,\"started_at\":\"2016-08-25T10:45:32Z\",\"finished_at\":\"2016-08-25T10:46:58Z\",\"duration\":86,\"commit\":\"90efd0e524f0832bfa98cd02ceb63ed86990f147\",\"branch\":\"master\",\"message\":\"Enable XML documentation\",\"event_type\":\"push\"},{\"id\":155010910,\"repository_id\":9577945,\"number\":\"30\",\"state\":\"finished\",\"result\":0,\"started_at\":\"2016-08-25T10:23:58Z\",\"finished_at\":\"2016-08-25T10:25:30Z\",\"duration\":92,\"commit\":\"eb1abd7422457a1db5ea8f5d0bfc37a96cb4fffc\",\"branch\":\"master\",\"message\":\"Updated nuget project icon\",\"event_type\":\"push\"}]"
+	coverallsJson = "{\"created_at\":\"2016-08-25T11:08:12Z\",\"url\":null,\"commit_message\":\"Clean up comments\",\"branch\":\"master\",\"committer_name\":\"Donovan Solms\",\"committer_email\":\"donovan@projectlimitless.io\",\"commit_sha\":\"48e98e50dbdc0a94a899f8c39baeb1f713183870\",\"repo_name\":\"ProjectLimitless/ioRPC\",\"badge_url\":\"https://s3.amazonaws.com/assets.coveralls.io/badges/coveralls_87.svg\",\"coverage_change\":0.0,\"covered_percent\":87.46}"
+	codecovJson = "{\"name\":\"ioRPC\",\"private\":false,\"updatestamp\":\"2016-08-25T11:08:12Z\",\"author\":{\"service\":\"github\",\"username\":\"ProjectLimitless\"},\"language\":\"c#\",\"branch\":\"master\",\"active\":true,\"activated\":true,\"totals\":{\"files\":12,\"lines\":400,\"hits\":350,\"misses\":50,\"partials\":0,\"coverage\":87.5}}"
+	gitHubJson = "[{\"tag_name\":\"v1.1.0-beta\",\"name\":\"Next\",\"draft\":true,\"prerelease\":true,\"published_at\":null},{\"tag_name\":\"v1.0.0\",\"name\":\"First release\",\"draft\":false,\"prerelease\":false,\"published_at\":\"2016-08-25T11:10:00Z\",\"author\":{\"login\":\"donovansolms\"}}]"
 	os.Exit(m.Run())
 }
@@ -31,6 +31,12 @@ type Parser interface {
 	Name() string
 }
 
+// BranchParser is implemented by the parsers that can pick the build of a
+// branch from the provider's response
+type BranchParser interface {
+	ParseBranch(raw []byte, branch string) (ProviderResult, error)
+}
+
 // ProviderResult creats a standard result set for multiple CI tools
 type ProviderResult struct {
 	// The proper name of the CI tool that provided this result
@@ -47,6 +53,31 @@ type ProviderResult struct {
 	CommitMessage string
 	// The last build time as provided
 	BuildDateTime time.Time
+	// The branch of the last build if the provider has it
+	Branch string
 	// Any error that occurred
 	Error string
+	// Set when this is the last good result, served because the
+	// provider could not be queried
+	IsStale bool
+	// Set when the provider is rate limiting the requests
+	IsThrottled bool
+	// The circuit breaker state for the provider endpoint
+	CircuitState string
+	// The status type, ie. Build or Coverage, set by Badger
+	Type string
+	// The covered percentage for coverage providers
+	Coverage float64
+	// The latest release tag for release providers
+	Version string
+	// The test counts of the last build, TestsCount is zero if the
+	// provider doesn't report tests
+	TestsCount  int
+	TestsPassed int
+	TestsFailed int
+	// The jobs of the last build if the provider reports them, the status
+	// is computed from these when it is set
+	Jobs []JobResult
+	// The value shown on value badges and pages, ie. '87.5%'
+	Value string
 }
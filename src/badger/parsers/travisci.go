@@ -37,6 +37,20 @@ type TravisCIBuild struct {
 	Branch       string `json:"branch"`
 	Message      string `json:"message"`
 	EventType    string `json:"event_type"`
+	// Matrix are the jobs of the build if the response includes them
+	Matrix []TravisCIJob `json:"matrix"`
+}
+
+// TravisCIJob is the JSON API structure for the jobs of a Travis CI build
+type TravisCIJob struct {
+	ID     int    `json:"id"`
+	Number string `json:"number"`
+	State  string `json:"state"`
+	// Result is null until the job has finished
+	Result       *int   `json:"result"`
+	AllowFailure bool   `json:"allow_failure"`
+	StartedAt    string `json:"started_at"`
+	FinishedAt   string `json:"finished_at"`
 }
 
 // Parse parses the json bytes into a provider result
@@ -49,33 +63,84 @@ func (parser *TravisCIParser) Parse(raw []byte) (ProviderResult, error) {
 	if err != nil {
 		return result, err
 	}
+	return parser.parseBuilds(result, builds)
+}
 
+// ParseBranch parses the json bytes into a provider result from the latest
+// build of branch
+func (parser *TravisCIParser) ParseBranch(raw []byte, branch string) (ProviderResult, error) {
+	var result ProviderResult
+	result.ProperName = parser.Name()
+	result.Provider = "TravisCI"
+	var builds []TravisCIBuild
+	err := json.Unmarshal(raw, &builds)
+	if err != nil {
+		return result, err
+	}
+	branchBuilds := make([]TravisCIBuild, 0, len(builds))
+	for _, build := range builds {
+		if build.Branch == branch {
+			branchBuilds = append(branchBuilds, build)
+		}
+	}
+	if len(builds) > 0 && len(branchBuilds) == 0 {
+		return result, errors.New("No builds found for branch '" + branch + "' on Travis CI")
+	}
+	return parser.parseBuilds(result, branchBuilds)
+}
+
+// parseBuilds fills in the result from the latest build
+func (parser *TravisCIParser) parseBuilds(result ProviderResult, builds []TravisCIBuild) (ProviderResult, error) {
 	if len(builds) > 0 {
 		build := builds[0]
-		switch build.Result {
-		case 0:
-			result.Status = ProviderStatusSuccess
-			result.IsSuccess = true
-		case 1:
-			result.Status = ProviderStatusFailed
-		default:
-			result.Status = ProviderStatusUnknown
-		}
-		//result.BuildDateTime = build.FinishedAt
-		if build.FinishedAt != "" && build.FinishedAt != "null" {
-			parsed, err := time.Parse("2006-01-02T15:04:05Z07:00", build.FinishedAt)
-			if err == nil {
-				result.BuildDateTime = parsed
+		for _, job := range build.Matrix {
+			status := ProviderStatusUnknown
+			if job.Result != nil {
+				status = travisCIStatus(*job.Result)
 			}
+			result.Jobs = append(result.Jobs, JobResult{
+				Name:         "Job " + job.Number,
+				Status:       status,
+				Duration:     jobDuration(travisCITime(job.StartedAt), travisCITime(job.FinishedAt)),
+				AllowFailure: job.AllowFailure,
+			})
 		}
+		result.Status = jobsStatus(result.Jobs, travisCIStatus(build.Result))
+		result.IsSuccess = result.Status == ProviderStatusSuccess
+		result.BuildDateTime = travisCITime(build.FinishedAt)
 		result.CommitMessage = build.Message
 		result.CommitUser = "Unknown"
+		result.Branch = build.Branch
 		return result, nil
 
 	}
 	return result, errors.New("No builds found for Travis CI")
 }
 
+// travisCIStatus converts the result of a build or job
+func travisCIStatus(result int) string {
+	switch result {
+	case 0:
+		return ProviderStatusSuccess
+	case 1:
+		return ProviderStatusFailed
+	default:
+		return ProviderStatusUnknown
+	}
+}
+
+// travisCITime parses a Travis CI timestamp, zero if it isn't set
+func travisCITime(timestamp string) time.Time {
+	if timestamp == "" || timestamp == "null" {
+		return time.Time{}
+	}
+	parsed, err := time.Parse("2006-01-02T15:04:05Z07:00", timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
 // Name returns the Proper name of the provider for the parser
 func (parser *TravisCIParser) Name() string {
 	return "Travis CI"
@@ -13,7 +13,12 @@
 
 package parsers_test
 
-import "testing"
+import (
+	"testing"
+	"time"
+
+	parsers "."
+)
 
 func TestTravisCIName(t *testing.T) {
 	expected := "Travis CI"
@@ -61,3 +66,52 @@ func TestTravisCIParseInvalidJSON(t *testing.T) {
 		t.Error("Parsing should have returned an error for invalid JSON")
 	}
 }
+
+func TestTravisCIParseBranch(t *testing.T) {
+	builds := `[{"result": 1, "branch": "release-2.x", "message": "Fix the release"},` +
+		`{"result": 0, "branch": "master", "message": "Clean up comments"}]`
+	branchParser := travisCIParser.(parsers.BranchParser)
+	parseResult, err := branchParser.ParseBranch([]byte(builds), "master")
+	if err != nil {
+		t.Errorf("Unable to parse TravisCI JSON for branch: %s", err.Error())
+	}
+	if parseResult.Status != "Passing" || parseResult.CommitMessage != "Clean up comments" {
+		t.Errorf("Build should be the latest build of master and not '%s' '%s'", parseResult.Status, parseResult.CommitMessage)
+	}
+	parseResult, err = branchParser.ParseBranch([]byte(builds), "release-2.x")
+	if err != nil || parseResult.Status != "Failing" {
+		t.Errorf("Build should be the latest build of release-2.x")
+	}
+	_, err = branchParser.ParseBranch([]byte(builds), "unknown")
+	if err == nil {
+		t.Error("Parsing should have returned an error for a branch without builds")
+	}
+}
+
+func TestTravisCIParseMatrix(t *testing.T) {
+	builds := `[{"result": 1, "branch": "master", "message": "Add Go 1.8", "matrix": [
+        {"number": "32.1", "result": 0, "started_at": "2016-08-25T11:05:46Z", "finished_at": "2016-08-25T11:07:04Z"},
+        {"number": "32.2", "result": 1, "allow_failure": true},
+        {"number": "32.3", "result": null}
+    ]}]`
+	parseResult, err := travisCIParser.Parse([]byte(builds))
+	if err != nil {
+		t.Errorf("Unable to parse TravisCI JSON: %s", err.Error())
+	}
+	if parseResult.Status != "Unknown" {
+		t.Errorf("Status should be '%s' and not '%s'", "Unknown", parseResult.Status)
+	}
+	expected := []parsers.JobResult{
+		{Name: "Job 32.1", Status: "Passing", Duration: 78 * time.Second},
+		{Name: "Job 32.2", Status: "Failing", AllowFailure: true},
+		{Name: "Job 32.3", Status: "Unknown"},
+	}
+	if len(parseResult.Jobs) != len(expected) {
+		t.Fatalf("Jobs should have %d jobs and not %d", len(expected), len(parseResult.Jobs))
+	}
+	for i, job := range expected {
+		if parseResult.Jobs[i] != job {
+			t.Errorf("Job should be '%v' and not '%v'", job, parseResult.Jobs[i])
+		}
+	}
+}
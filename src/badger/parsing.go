@@ -30,6 +30,12 @@ func NewParser(parserType string) (parsers.Parser, error) {
 		return &parsers.TravisCIParser{}, nil
 	case ProviderAppveyor:
 		return &parsers.AppveyorParser{}, nil
+	case ProviderCoveralls:
+		return &parsers.CoverallsParser{}, nil
+	case ProviderCodecov:
+		return &parsers.CodecovParser{}, nil
+	case ProviderGitHub:
+		return &parsers.GitHubParser{}, nil
 	default:
 		return nil, errors.New("No parser found for " + parserType)
 	}
@@ -37,8 +43,35 @@ func NewParser(parserType string) (parsers.Parser, error) {
 
 // FetchStatus fetches the current status from a provider and returns
 // the parsed result
-func FetchStatus(status StatusConfig) (parsers.ProviderResult, error) {
-	var result parsers.ProviderResult
+func (fetcher *Fetcher) FetchStatus(status StatusConfig) (parsers.ProviderResult, error) {
+	start := time.Now()
+	result, err := fetcher.fetchStatus(status)
+	result.Type = statusType(status)
+	if err == nil || result.IsStale {
+		applyStatusType(status, &result)
+	}
+	provider := strings.ToLower(status.Provider)
+	fetcher.Metrics.observe(metricFetchDuration, time.Since(start), provider)
+	fetcher.Metrics.add(metricFetches, 1, provider)
+	if err != nil {
+		fetcher.Metrics.add(metricFetchErrors, 1, provider, fetchErrorReason(err))
+	}
+	return result, err
+}
+
+// fetchErrorReason is the metrics label for a fetch error
+func fetchErrorReason(err error) string {
+	switch err.(type) {
+	case *RateLimitError:
+		return "rate_limited"
+	case *CircuitOpenError:
+		return "circuit_open"
+	}
+	return "error"
+}
+
+// fetchStatus does the work for FetchStatus
+func (fetcher *Fetcher) fetchStatus(status StatusConfig) (result parsers.ProviderResult, err error) {
 	parser, err := NewParser(status.Provider)
 	if err != nil {
 		return result, err
@@ -54,19 +87,50 @@ func FetchStatus(status StatusConfig) (parsers.ProviderResult, error) {
 	*/
 
 	// Get the content from the URL
-	client := &http.Client{
-		Timeout: time.Second * 10,
-	}
 	request, err := http.NewRequest("GET", status.URL, nil)
 	if err != nil {
 		return result, err
 	}
+	// Don't hit a provider host while it is throttling us
+	host := request.URL.Host
+	cacheKey := strings.ToLower(status.Provider) + " " + status.URL
+	if status.branch != "" {
+		cacheKey += " " + status.branch
+	}
+	if rateLimitError := fetcher.limiter.blocked(host); rateLimitError != nil {
+		return fetcher.staleResult(result, cacheKey, rateLimitError)
+	}
+	// Or while it is down
+	endpoint := request.URL.Scheme + "://" + host
+	defer func() {
+		result.CircuitState = fetcher.breaker.state(endpoint)
+	}()
+	if circuitOpenError := fetcher.breaker.allow(endpoint); circuitOpenError != nil {
+		return fetcher.staleResult(result, cacheKey, circuitOpenError)
+	}
 	// Set the accept header so that we get JSON results
 	request.Header.Set("Accept", "application/json")
-	response, err := client.Do(request)
+	for name, value := range status.Headers {
+		request.Header.Set(name, value)
+	}
+	switch strings.ToLower(status.Auth.Scheme) {
+	case AuthSchemeBearer:
+		request.Header.Set("Authorization", "Bearer "+status.Auth.Token)
+	case AuthSchemeToken:
+		request.Header.Set("Authorization", "token "+status.Auth.Token)
+	case AuthSchemeBasic:
+		request.SetBasicAuth(status.Auth.Username, status.Auth.Password)
+	}
+	response, err := doWithRetries(fetcher.client, request, fetcher.timeoutFor(status.Provider), fetcher.policy)
 	if err != nil {
+		fetcher.breaker.record(endpoint, false, fetcher.policy)
 		return result, errors.New("Unable to fetch status")
 	}
+	defer response.Body.Close()
+	fetcher.breaker.record(endpoint, response.StatusCode < http.StatusInternalServerError, fetcher.policy)
+	if rateLimitError := fetcher.limiter.inspect(host, response); rateLimitError != nil {
+		return fetcher.staleResult(result, cacheKey, rateLimitError)
+	}
 	if response.StatusCode != http.StatusOK {
 		return result, errors.New("Unable to fetch status: '" + status.Provider + "':" + response.Status)
 	}
@@ -75,41 +139,76 @@ func FetchStatus(status StatusConfig) (parsers.ProviderResult, error) {
 	if err != nil {
 		return result, err
 	}
-	defer response.Body.Close()
 
 	if len(body) == 0 {
 		return result, errors.New("Data provided to parse is blank")
 	}
 
-	result, err = parser.Parse(body)
+	if branchParser, ok := parser.(parsers.BranchParser); ok && status.branch != "" {
+		result, err = branchParser.ParseBranch(body, status.branch)
+	} else {
+		result, err = parser.Parse(body)
+	}
 	if err != nil {
 		return result, err
 	}
+	// Anyone can request a branch, only keep the results of the branches
+	// that are refreshed so that the last good results don't grow forever
+	if !status.untracked {
+		fetcher.limiter.store(cacheKey, result)
+	}
 	return result, nil
 }
 
+// staleResult returns the last good result for a status that can not be
+// fetched right now if there is one, otherwise result is returned
+func (fetcher *Fetcher) staleResult(result parsers.ProviderResult, cacheKey string, err error) (parsers.ProviderResult, error) {
+	if stale, ok := fetcher.limiter.stale(cacheKey, err); ok {
+		fetcher.Metrics.add(metricCacheRequests, 1, "last_good", "hit")
+		return stale, err
+	}
+	fetcher.Metrics.add(metricCacheRequests, 1, "last_good", "miss")
+	_, result.IsThrottled = err.(*RateLimitError)
+	return result, err
+}
+
 // FetchAllStatuses fetches all provider statuses by calling FetchStatus for
 // each statuses provided. Does not return an error, errors are inserted into
 // returned provider results.
-func FetchAllStatuses(statuses []StatusConfig) (parsers.ProviderResult, map[string]parsers.ProviderResult) {
+func (fetcher *Fetcher) FetchAllStatuses(statuses []StatusConfig) (parsers.ProviderResult, map[string]parsers.ProviderResult) {
 	providerStatuses := make(map[string]parsers.ProviderResult)
+	for _, status := range statuses {
+		providerStatus, err := fetcher.FetchStatus(status)
+		if err != nil && providerStatus.IsStale {
+			// Keep showing the last good status while the provider is
+			// throttling us or unavailable
+			providerStatus.Error = Redact(err.Error())
+		} else if err != nil {
+			providerStatus.Status = parsers.ProviderStatusUnknown
+			// Errors are rendered on the public status pages
+			providerStatus.Error = Redact(err.Error())
+		}
+		// Always add
+		providerStatuses[statusKey(status)] = providerStatus
+	}
 	overallStatus := parsers.ProviderResult{
 		ProperName: "Overall",
 		Status:     parsers.ProviderStatusSuccess,
 	}
-	for _, status := range statuses {
-		providerStatus, err := FetchStatus(status)
-		if err != nil {
-			providerStatus.Status = parsers.ProviderStatusUnknown
-			providerStatus.Error = err.Error()
-			overallStatus.Status = parsers.ProviderStatusFailed
-		} else {
-			if providerStatus.Status != parsers.ProviderStatusSuccess {
-				overallStatus.Status = parsers.ProviderStatusFailed
-			}
+	for _, providerStatus := range providerStatuses {
+		if providerStatus.Type == StatusTypeRelease {
+			continue
 		}
-		// Always add
-		providerStatuses[strings.ToLower(status.Provider)] = providerStatus
+		overallStatus.Status = aggregateStatus(overallStatus.Status, providerStatus.Status)
 	}
 	return overallStatus, providerStatuses
 }
+
+// aggregateStatus combines an overall status with another status. The
+// overall status is only passing while everything is passing.
+func aggregateStatus(overall string, status string) string {
+	if status != parsers.ProviderStatusSuccess {
+		return parsers.ProviderStatusFailed
+	}
+	return overall
+}
@@ -0,0 +1,318 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	badger "."
+)
+
+const appVeyorStatusJSON = `{"build": {"status": "success", "message": "Clean up comments", "committerName": "Donovan Solms"}}`
+
+// newStatusServer creates a test server that serves an AppVeyor status and
+// passes each request to check
+func newStatusServer(t *testing.T, check func(r *http.Request)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		check(r)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(appVeyorStatusJSON))
+	}))
+}
+
+// newFetcher creates a fetcher with the default transport
+func newFetcher(t *testing.T, config badger.FetchConfig) *badger.Fetcher {
+	fetcher, err := badger.NewFetcher(config)
+	if err != nil {
+		t.Fatalf("Unable to create fetcher: %s", err.Error())
+	}
+	return fetcher
+}
+
+func TestFetchStatusHeaders(t *testing.T) {
+	server := newStatusServer(t, func(r *http.Request) {
+		if r.Header.Get("Accept") != "application/json" {
+			t.Errorf("Accept should be '%s' and not '%s'", "application/json", r.Header.Get("Accept"))
+		}
+		if r.Header.Get("Travis-API-Version") != "3" {
+			t.Errorf("Travis-API-Version should be '%s' and not '%s'", "3", r.Header.Get("Travis-API-Version"))
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "gitlab-token" {
+			t.Errorf("PRIVATE-TOKEN should be '%s' and not '%s'", "gitlab-token", r.Header.Get("PRIVATE-TOKEN"))
+		}
+	})
+	defer server.Close()
+
+	result, err := newFetcher(t, badger.FetchConfig{}).FetchStatus(badger.StatusConfig{
+		Provider: "AppVeyor",
+		URL:      server.URL,
+		Headers: map[string]string{
+			"Travis-API-Version": "3",
+			"PRIVATE-TOKEN":      "gitlab-token",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Unable to fetch status: %s", err.Error())
+	}
+	if result.IsSuccess != true {
+		t.Errorf("IsSuccess should be true")
+	}
+}
+
+func TestFetchStatusAuth(t *testing.T) {
+	tests := []struct {
+		name          string
+		auth          badger.StatusAuthConfig
+		authorization string
+	}{
+		{"Bearer", badger.StatusAuthConfig{Scheme: "Bearer", Token: "abc123"}, "Bearer abc123"},
+		{"Token", badger.StatusAuthConfig{Scheme: "token", Token: "abc123"}, "token abc123"},
+		{"Basic", badger.StatusAuthConfig{Scheme: "basic", Username: "badger", Password: "secret"}, "Basic YmFkZ2VyOnNlY3JldA=="},
+		{"None", badger.StatusAuthConfig{}, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newStatusServer(t, func(r *http.Request) {
+				if r.Header.Get("Authorization") != test.authorization {
+					t.Errorf("Authorization should be '%s' and not '%s'", test.authorization, r.Header.Get("Authorization"))
+				}
+			})
+			defer server.Close()
+
+			_, err := newFetcher(t, badger.FetchConfig{}).FetchStatus(badger.StatusConfig{
+				Provider: "AppVeyor",
+				URL:      server.URL,
+				Auth:     test.auth,
+			})
+			if err != nil {
+				t.Errorf("Unable to fetch status: %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestFetchStatusRateLimited(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests > 1 {
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(appVeyorStatusJSON))
+	}))
+	defer server.Close()
+	status := badger.StatusConfig{
+		Provider: "AppVeyor",
+		URL:      server.URL,
+	}
+	fetcher := newFetcher(t, badger.FetchConfig{})
+
+	_, err := fetcher.FetchStatus(status)
+	if err != nil {
+		t.Fatalf("Unable to fetch status: %s", err.Error())
+	}
+
+	t.Run("Throttled", func(t *testing.T) {
+		result, err := fetcher.FetchStatus(status)
+		rateLimitError, ok := err.(*badger.RateLimitError)
+		if !ok {
+			t.Fatalf("Error should be a rate limit error and not '%v'", err)
+		}
+		if rateLimitError.Until.Before(time.Now().Add(119 * time.Second)) {
+			t.Errorf("Backoff should respect Retry-After, got %s", rateLimitError.Until)
+		}
+		if !result.IsStale || !result.IsThrottled || !result.IsSuccess {
+			t.Errorf("The last good result should be served as stale and throttled, got %+v", result)
+		}
+	})
+
+	t.Run("BackedOff", func(t *testing.T) {
+		_, err := fetcher.FetchStatus(status)
+		if _, ok := err.(*badger.RateLimitError); !ok {
+			t.Errorf("Error should be a rate limit error and not '%v'", err)
+		}
+		if requests != 2 {
+			t.Errorf("The provider should not be called while backing off, got %d requests", requests)
+		}
+	})
+
+	t.Run("Overall", func(t *testing.T) {
+		overall, providers := fetcher.FetchAllStatuses([]badger.StatusConfig{status})
+		if overall.Status != "Passing" {
+			t.Errorf("Overall status should stay '%s' and not '%s'", "Passing", overall.Status)
+		}
+		if providers["appveyor"].Error == "" {
+			t.Errorf("The throttled provider should report an error")
+		}
+	})
+}
+
+func TestFetchStatusCircuitBreaker(t *testing.T) {
+	fetcher := newFetcher(t, badger.FetchConfig{
+		Retries:                1,
+		RetryBackoffMS:         1,
+		BreakerThreshold:       2,
+		BreakerCooldownSeconds: 60,
+	})
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	status := badger.StatusConfig{
+		Provider: "AppVeyor",
+		URL:      server.URL,
+	}
+
+	t.Run("Retries", func(t *testing.T) {
+		result, err := fetcher.FetchStatus(status)
+		if err == nil {
+			t.Errorf("Fetching from an unavailable provider should fail")
+		}
+		if requests != 2 {
+			t.Errorf("The request should be retried once, got %d requests", requests)
+		}
+		if result.CircuitState != badger.CircuitClosed {
+			t.Errorf("CircuitState should be '%s' and not '%s'", badger.CircuitClosed, result.CircuitState)
+		}
+	})
+
+	t.Run("Opens", func(t *testing.T) {
+		result, _ := fetcher.FetchStatus(status)
+		if result.CircuitState != badger.CircuitOpen {
+			t.Errorf("CircuitState should be '%s' and not '%s'", badger.CircuitOpen, result.CircuitState)
+		}
+	})
+
+	t.Run("Open", func(t *testing.T) {
+		result, err := fetcher.FetchStatus(status)
+		if _, ok := err.(*badger.CircuitOpenError); !ok {
+			t.Errorf("Error should be a circuit open error and not '%v'", err)
+		}
+		if requests != 4 {
+			t.Errorf("The provider should not be called while the circuit is open, got %d requests", requests)
+		}
+		if result.CircuitState != badger.CircuitOpen {
+			t.Errorf("CircuitState should be '%s' and not '%s'", badger.CircuitOpen, result.CircuitState)
+		}
+	})
+}
+
+// roundTripperFunc allows a function to be used as a transport
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+func TestFetcherTransport(t *testing.T) {
+	transport := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Host != "ci.appveyor.com" {
+			t.Errorf("Host should be '%s' and not '%s'", "ci.appveyor.com", r.URL.Host)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Header:     make(http.Header),
+			Body:       ioutil.NopCloser(strings.NewReader(appVeyorStatusJSON)),
+			Request:    r,
+		}, nil
+	})
+	fetcher := badger.NewFetcherWithTransport(badger.FetchConfig{}, transport)
+
+	result, err := fetcher.FetchStatus(badger.StatusConfig{
+		Provider: "AppVeyor",
+		URL:      "https://ci.appveyor.com/api/projects/donovansolms/ioRPC",
+	})
+	if err != nil {
+		t.Fatalf("Unable to fetch status: %s", err.Error())
+	}
+	if result.CommitMessage != "Clean up comments" {
+		t.Errorf("CommitMessage should be '%s' and not '%s'", "Clean up comments", result.CommitMessage)
+	}
+}
+
+func TestFetcherCACertFiles(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(appVeyorStatusJSON))
+	}))
+	defer server.Close()
+	status := badger.StatusConfig{
+		Provider: "AppVeyor",
+		URL:      server.URL,
+	}
+
+	t.Run("Untrusted", func(t *testing.T) {
+		_, err := newFetcher(t, badger.FetchConfig{Retries: -1}).FetchStatus(status)
+		if err == nil {
+			t.Errorf("A server with an unknown CA should not be trusted")
+		}
+	})
+
+	t.Run("Trusted", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "badger")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+		caFile := filepath.Join(dir, "ca.pem")
+		caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		err = ioutil.WriteFile(caFile, caPEM, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = newFetcher(t, badger.FetchConfig{CACertFiles: []string{caFile}}).FetchStatus(status)
+		if err != nil {
+			t.Errorf("Unable to fetch status with a custom CA: %s", err.Error())
+		}
+	})
+}
+
+func TestFetcherProviderTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(1500 * time.Millisecond)
+		w.Write([]byte(appVeyorStatusJSON))
+	}))
+	defer server.Close()
+
+	fetcher := newFetcher(t, badger.FetchConfig{
+		Retries:                -1,
+		TimeoutSeconds:         10,
+		ProviderTimeoutSeconds: map[string]int{"AppVeyor": 1},
+	})
+	start := time.Now()
+	_, err := fetcher.FetchStatus(badger.StatusConfig{
+		Provider: "AppVeyor",
+		URL:      server.URL,
+	})
+	if err == nil {
+		t.Errorf("The request should time out")
+	}
+	if time.Since(start) > 1400*time.Millisecond {
+		t.Errorf("The AppVeyor timeout should apply, took %s", time.Since(start))
+	}
+}
@@ -0,0 +1,177 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"./parsers"
+)
+
+const (
+	// rateLimitBackoff is the initial backoff when a provider throttles
+	// without telling us for how long
+	rateLimitBackoff = 30 * time.Second
+	// rateLimitMaxBackoff caps the backoff for a single host
+	rateLimitMaxBackoff = 15 * time.Minute
+	// rateLimitJitter is the fraction of the backoff added as jitter so
+	// that requests to a host don't all resume at the same moment
+	rateLimitJitter = 0.1
+)
+
+// RateLimitError is returned when a provider host is throttling requests
+type RateLimitError struct {
+	Host  string
+	Until time.Time
+}
+
+// Error implements error
+func (rateLimitError *RateLimitError) Error() string {
+	return fmt.Sprintf("Rate limited by %s, retrying after %s",
+		rateLimitError.Host,
+		rateLimitError.Until.UTC().Format(time.RFC1123))
+}
+
+// hostBackoff is the throttling state for a single provider host
+type hostBackoff struct {
+	until time.Time
+	// strikes counts consecutive throttled responses
+	strikes uint
+}
+
+// rateLimiter tracks the rate limit state per provider host and keeps the
+// last good result per status so it can be served while the provider is
+// throttled or unavailable
+type rateLimiter struct {
+	sync.Mutex
+	hosts       map[string]*hostBackoff
+	lastResults map[string]parsers.ProviderResult
+}
+
+// newRateLimiter creates an empty rate limiter
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		hosts:       make(map[string]*hostBackoff),
+		lastResults: make(map[string]parsers.ProviderResult),
+	}
+}
+
+// blocked returns the error to report if host is currently being backed off
+func (limiter *rateLimiter) blocked(host string) *RateLimitError {
+	limiter.Lock()
+	defer limiter.Unlock()
+	backoff, ok := limiter.hosts[host]
+	if !ok || time.Now().After(backoff.until) {
+		return nil
+	}
+	return &RateLimitError{Host: host, Until: backoff.until}
+}
+
+// inspect reads the rate limit headers of a response. If the response was
+// throttled, or the remaining quota is used up, the host is backed off and
+// the error to report is returned.
+func (limiter *rateLimiter) inspect(host string, response *http.Response) *RateLimitError {
+	throttled := response.StatusCode == http.StatusTooManyRequests ||
+		(response.StatusCode == http.StatusForbidden && response.Header.Get("X-RateLimit-Remaining") == "0")
+	exhausted := response.Header.Get("X-RateLimit-Remaining") == "0"
+
+	limiter.Lock()
+	defer limiter.Unlock()
+	backoff, ok := limiter.hosts[host]
+	if !ok {
+		backoff = &hostBackoff{}
+		limiter.hosts[host] = backoff
+	}
+	if !throttled && !exhausted {
+		backoff.strikes = 0
+		return nil
+	}
+
+	wait, ok := retryAfter(response.Header, time.Now())
+	if !ok {
+		if !throttled {
+			// The quota is used up but we don't know when it resets,
+			// the next request will tell us
+			return nil
+		}
+		wait = rateLimitBackoff << backoff.strikes
+		if wait > rateLimitMaxBackoff || wait <= 0 {
+			wait = rateLimitMaxBackoff
+		}
+	}
+	if throttled {
+		backoff.strikes++
+	}
+	wait += time.Duration(rand.Float64() * rateLimitJitter * float64(wait))
+	backoff.until = time.Now().Add(wait)
+	if !throttled {
+		// This response is still good, only the next requests wait
+		return nil
+	}
+	return &RateLimitError{Host: host, Until: backoff.until}
+}
+
+// store keeps a good result for the status at key
+func (limiter *rateLimiter) store(key string, result parsers.ProviderResult) {
+	limiter.Lock()
+	defer limiter.Unlock()
+	limiter.lastResults[key] = result
+}
+
+// count returns the number of last good results kept
+func (limiter *rateLimiter) count() int {
+	limiter.Lock()
+	defer limiter.Unlock()
+	return len(limiter.lastResults)
+}
+
+// stale returns the last good result for the status at key, marked as
+// stale with err as the reason
+func (limiter *rateLimiter) stale(key string, err error) (parsers.ProviderResult, bool) {
+	limiter.Lock()
+	defer limiter.Unlock()
+	result, ok := limiter.lastResults[key]
+	if !ok {
+		return result, false
+	}
+	result.IsStale = true
+	_, result.IsThrottled = err.(*RateLimitError)
+	result.Error = err.Error()
+	return result, true
+}
+
+// retryAfter determines how long to wait before the next request based on
+// the Retry-After and X-RateLimit-Reset headers
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return date.Sub(now), date.After(now)
+		}
+	}
+	if value := header.Get("X-RateLimit-Reset"); value != "" {
+		if epoch, err := strconv.ParseInt(value, 10, 64); err == nil {
+			reset := time.Unix(epoch, 0)
+			return reset.Sub(now), reset.After(now)
+		}
+	}
+	return 0, false
+}
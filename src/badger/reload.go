@@ -0,0 +1,210 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ReloadResult lists the project slugs affected by a reload
+type ReloadResult struct {
+	Added   []string `json:"Added"`
+	Removed []string `json:"Removed"`
+	Changed []string `json:"Changed"`
+	// Files that failed to load, their previous config is kept unless
+	// its slug is claimed by another file
+	Failed map[string]string `json:"Failed"`
+}
+
+// loadProjectFiles reads all the project files in projectsPath. Files that
+// could not be read or parsed are returned in the failed map with the reason.
+func loadProjectFiles(projectsPath string) (map[string]ProjectConfig, map[string]string, error) {
+	paths, err := ProjectFiles(projectsPath)
+	if err != nil {
+		return nil, nil, errors.New("Unable to open project path '" + projectsPath + "'")
+	}
+
+	loaded := make(map[string]ProjectConfig)
+	failed := make(map[string]string)
+	for _, path := range paths {
+		fileName := filepath.Base(path)
+		fileBytes, err := ioutil.ReadFile(path)
+		if err != nil {
+			failed[fileName] = fmt.Sprintf("Unable to read project file '%s': %s", path, err.Error())
+			continue
+		}
+		projectConfig, validationErrors := decodeProjectFile(fileName, fileBytes)
+		if len(validationErrors) > 0 {
+			failed[fileName] = fmt.Sprintf("Invalid project file '%s': %s", fileName, joinValidationErrors(validationErrors))
+			continue
+		}
+		loaded[fileName] = projectConfig
+	}
+	return loaded, failed, nil
+}
+
+// Reload re-reads the project files of every site and swaps them in
+// atomically. A file that fails to load keeps serving its previously
+// loaded config. The templates are parsed again. Projects of virtual
+// hosts are listed as 'name/slug'.
+func (badger *Badger) Reload() (ReloadResult, error) {
+	result := ReloadResult{
+		Added:   make([]string, 0),
+		Removed: make([]string, 0),
+		Changed: make([]string, 0),
+		Failed:  make(map[string]string),
+	}
+	badger.loadTemplates()
+	siteFiles := make([]map[string]ProjectConfig, len(badger.sites))
+	siteFailed := make([]map[string]string, len(badger.sites))
+	for i, site := range badger.sites {
+		loaded, failed, err := loadProjectFiles(site.projectsPath)
+		if err != nil {
+			badger.log.Error("Reload failed: %s", err.Error())
+			return result, err
+		}
+		siteFiles[i] = loaded
+		siteFailed[i] = failed
+		for fileName, reason := range failed {
+			result.Failed[site.key(fileName)] = reason
+		}
+	}
+
+	badger.projectsLock.Lock()
+	defer badger.projectsLock.Unlock()
+
+	siteProjects := make([]map[string]ProjectConfig, len(badger.sites))
+	siteAliases := make([]map[string]string, len(badger.sites))
+	for i, site := range badger.sites {
+		loaded := siteFiles[i]
+		for fileName, reason := range siteFailed[i] {
+			badger.log.Warning(reason)
+			if previous, ok := site.projectFiles[fileName]; ok {
+				badger.log.Warning("Keeping previous config for '%s'", previous.Name)
+				loaded[fileName] = previous
+			}
+		}
+		projects, aliases, conflicts := indexProjects(loaded)
+		for fileName, reason := range conflicts {
+			badger.log.Warning(reason)
+			result.Failed[site.key(fileName)] = reason
+			delete(loaded, fileName)
+		}
+		if len(projects) == 0 {
+			badger.log.Error("Reload found no project configs for the %s site, keeping the current configs", site.label())
+			return result, errors.New("No project configs loaded")
+		}
+		siteProjects[i] = projects
+		siteAliases[i] = aliases
+	}
+
+	// affected keeps the new configs by site qualified slug
+	affected := make(map[string]ProjectConfig)
+	projectCount := 0
+	for i, site := range badger.sites {
+		projects := siteProjects[i]
+		projectCount += len(projects)
+		for slug, projectConfig := range projects {
+			previous, ok := site.projects[slug]
+			if !ok {
+				result.Added = append(result.Added, site.key(slug))
+				affected[site.key(slug)] = projectConfig
+			} else if !reflect.DeepEqual(previous, projectConfig) {
+				result.Changed = append(result.Changed, site.key(slug))
+				affected[site.key(slug)] = projectConfig
+			}
+		}
+		for slug := range site.projects {
+			if _, ok := projects[slug]; !ok {
+				result.Removed = append(result.Removed, site.key(slug))
+			}
+		}
+		site.projectFiles = siteFiles[i]
+		site.projects = projects
+		site.aliases = siteAliases[i]
+	}
+	sort.Strings(result.Added)
+	sort.Strings(result.Removed)
+	sort.Strings(result.Changed)
+
+	for _, key := range result.Added {
+		badger.log.Info("Project '%s' added", key)
+		badger.warnProjectAssets(affected[key])
+	}
+	for _, key := range result.Removed {
+		badger.log.Info("Project '%s' removed", key)
+		badger.Metrics.forgetProject(key)
+		badger.statuses.forget(key)
+	}
+	for _, key := range result.Changed {
+		badger.log.Info("Project '%s' changed", key)
+		badger.statuses.forget(key)
+		badger.warnProjectAssets(affected[key])
+	}
+	badger.log.Info("Reload complete, %d project(s) loaded", projectCount)
+	return result, nil
+}
+
+// warnProjectAssets logs problems with a project's badge images. These are
+// not fatal since the badge handler falls back to the default background.
+func (badger *Badger) warnProjectAssets(projectConfig ProjectConfig) {
+	for _, validationError := range ValidateProjectAssets(projectConfig, badger.BadgesPath) {
+		badger.log.Warning("Project '%s': %s", projectConfig.Name, validationError.Error())
+	}
+}
+
+// Project returns the config for the project with the given slug on the
+// default site
+func (badger *Badger) Project(slug string) (ProjectConfig, bool) {
+	return badger.siteProject(badger.defaultSite(), slug)
+}
+
+// Projects returns a copy of all the projects loaded on the default site
+// keyed by slug
+func (badger *Badger) Projects() map[string]ProjectConfig {
+	return badger.siteProjects(badger.defaultSite())
+}
+
+// ReloadHandler handles calls to /admin/reload. The request must carry the
+// configured admin token as a bearer token.
+func (badger *Badger) ReloadHandler(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(badger.adminToken)) != 1 {
+		badger.log.Warning("Unauthorized reload request from %s", r.RemoteAddr)
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte("Unauthorized"))
+		return
+	}
+
+	result, err := badger.Reload()
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(fmt.Sprintf("Reload failed: %s", err.Error())))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(result)
+	if err != nil {
+		badger.log.Error("Unable to write reload result: %s", err.Error())
+	}
+}
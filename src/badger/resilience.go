@@ -0,0 +1,235 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// CircuitClosed is the state of a healthy endpoint
+	CircuitClosed = "closed"
+	// CircuitOpen is the state of an endpoint that is not being called
+	CircuitOpen = "open"
+	// CircuitHalfOpen is the state of an endpoint that is being probed
+	// after its cool-down
+	CircuitHalfOpen = "half-open"
+
+	// defaultRetries is the number of retries when none are configured
+	defaultRetries = 2
+	// defaultRetryBackoff is the first retry backoff, doubled on every retry
+	defaultRetryBackoff = 250 * time.Millisecond
+	// defaultBreakerThreshold is the number of consecutive failures
+	// that opens the circuit for an endpoint
+	defaultBreakerThreshold = 5
+	// defaultBreakerCooldown is how long an open circuit stays open
+	defaultBreakerCooldown = 60 * time.Second
+)
+
+// CircuitOpenError is returned when the circuit for a provider endpoint is
+// open and the endpoint is not being called
+type CircuitOpenError struct {
+	Endpoint string
+	Until    time.Time
+}
+
+// Error implements error
+func (circuitOpenError *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s is unavailable, retrying after %s",
+		circuitOpenError.Endpoint,
+		circuitOpenError.Until.UTC().Format(time.RFC1123))
+}
+
+// fetchPolicy holds the retry and circuit breaker settings
+type fetchPolicy struct {
+	retries          int
+	retryBackoff     time.Duration
+	breakerThreshold int
+	breakerCooldown  time.Duration
+}
+
+// newFetchPolicy applies the defaults to the fetch config
+func newFetchPolicy(config FetchConfig) fetchPolicy {
+	policy := fetchPolicy{
+		retries:          config.Retries,
+		retryBackoff:     time.Duration(config.RetryBackoffMS) * time.Millisecond,
+		breakerThreshold: config.BreakerThreshold,
+		breakerCooldown:  time.Duration(config.BreakerCooldownSeconds) * time.Second,
+	}
+	if policy.retries == 0 {
+		policy.retries = defaultRetries
+	} else if policy.retries < 0 {
+		policy.retries = 0
+	}
+	if policy.retryBackoff <= 0 {
+		policy.retryBackoff = defaultRetryBackoff
+	}
+	if policy.breakerThreshold == 0 {
+		policy.breakerThreshold = defaultBreakerThreshold
+	}
+	if policy.breakerCooldown <= 0 {
+		policy.breakerCooldown = defaultBreakerCooldown
+	}
+	return policy
+}
+
+// circuit is the breaker state for a single endpoint
+type circuit struct {
+	state    string
+	failures int
+	until    time.Time
+	// probing is set while the single half-open request is in flight
+	probing bool
+}
+
+// circuitBreaker tracks a circuit per provider endpoint
+type circuitBreaker struct {
+	sync.Mutex
+	circuits map[string]*circuit
+}
+
+// newCircuitBreaker creates a circuit breaker with all circuits closed
+func newCircuitBreaker() *circuitBreaker {
+	return &circuitBreaker{
+		circuits: make(map[string]*circuit),
+	}
+}
+
+// circuitFor returns the circuit for endpoint, the lock must be held
+func (breaker *circuitBreaker) circuitFor(endpoint string) *circuit {
+	endpointCircuit, ok := breaker.circuits[endpoint]
+	if !ok {
+		endpointCircuit = &circuit{state: CircuitClosed}
+		breaker.circuits[endpoint] = endpointCircuit
+	}
+	return endpointCircuit
+}
+
+// allow checks if endpoint may be called. Once the cool-down has passed a
+// single request is let through to probe the endpoint.
+func (breaker *circuitBreaker) allow(endpoint string) *CircuitOpenError {
+	breaker.Lock()
+	defer breaker.Unlock()
+	endpointCircuit := breaker.circuitFor(endpoint)
+	switch endpointCircuit.state {
+	case CircuitOpen:
+		if time.Now().Before(endpointCircuit.until) {
+			return &CircuitOpenError{Endpoint: endpoint, Until: endpointCircuit.until}
+		}
+		endpointCircuit.state = CircuitHalfOpen
+		endpointCircuit.probing = true
+	case CircuitHalfOpen:
+		if endpointCircuit.probing {
+			return &CircuitOpenError{Endpoint: endpoint, Until: endpointCircuit.until}
+		}
+		endpointCircuit.probing = true
+	}
+	return nil
+}
+
+// record updates the circuit for endpoint with the outcome of a request
+func (breaker *circuitBreaker) record(endpoint string, success bool, policy fetchPolicy) {
+	breaker.Lock()
+	defer breaker.Unlock()
+	endpointCircuit := breaker.circuitFor(endpoint)
+	endpointCircuit.probing = false
+	if success {
+		endpointCircuit.state = CircuitClosed
+		endpointCircuit.failures = 0
+		return
+	}
+	endpointCircuit.failures++
+	if policy.breakerThreshold < 0 {
+		return
+	}
+	if endpointCircuit.state == CircuitHalfOpen || endpointCircuit.failures >= policy.breakerThreshold {
+		endpointCircuit.state = CircuitOpen
+		endpointCircuit.until = time.Now().Add(policy.breakerCooldown)
+	}
+}
+
+// state returns the current state of the circuit for endpoint
+func (breaker *circuitBreaker) state(endpoint string) string {
+	breaker.Lock()
+	defer breaker.Unlock()
+	return breaker.circuitFor(endpoint).state
+}
+
+// states returns the current state of all the known circuits by endpoint
+func (breaker *circuitBreaker) states() map[string]string {
+	breaker.Lock()
+	defer breaker.Unlock()
+	states := make(map[string]string, len(breaker.circuits))
+	for endpoint, endpointCircuit := range breaker.circuits {
+		states[endpoint] = endpointCircuit.state
+	}
+	return states
+}
+
+// isRetryableStatus checks if a response status is worth retrying
+func isRetryableStatus(statusCode int) bool {
+	return statusCode == http.StatusBadGateway ||
+		statusCode == http.StatusServiceUnavailable ||
+		statusCode == http.StatusGatewayTimeout
+}
+
+// doWithRetries sends an idempotent request, retrying transport errors and
+// gateway errors with exponential backoff and jitter. Each attempt gets its
+// own timeout.
+func doWithRetries(client *http.Client, request *http.Request, timeout time.Duration, policy fetchPolicy) (*http.Response, error) {
+	backoff := policy.retryBackoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(request.Context(), timeout)
+		response, err := client.Do(request.WithContext(ctx))
+		if err != nil {
+			cancel()
+		} else {
+			// The timeout applies until the body has been read
+			response.Body = cancelOnClose{response.Body, cancel}
+		}
+		if attempt >= policy.retries {
+			return response, err
+		}
+		if err == nil && !isRetryableStatus(response.StatusCode) {
+			return response, nil
+		}
+		if response != nil {
+			// Drain the body so the connection can be reused
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+		}
+		time.Sleep(backoff + time.Duration(rand.Int63n(int64(backoff)/2+1)))
+		backoff *= 2
+	}
+}
+
+// cancelOnClose cancels the request context once the body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close implements io.Closer
+func (body cancelOnClose) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
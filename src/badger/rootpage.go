@@ -0,0 +1,212 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"./parsers"
+)
+
+const (
+	// RootSortName sorts the root page projects by name
+	RootSortName = "name"
+	// RootSortStatus sorts the failing projects first
+	RootSortStatus = "status"
+	// RootSortUpdated sorts the most recently built projects first
+	RootSortUpdated = "updated"
+
+	// RootGroupStatus groups the root page projects by overall status
+	RootGroupStatus = "status"
+	// RootGroupGroup groups the root page projects by their project group
+	RootGroupGroup = "group"
+)
+
+// ungroupedName is the heading of the projects without a group
+const ungroupedName = "Ungrouped"
+
+// minRootRefresh is the shortest auto refresh interval of the root page
+const minRootRefresh = 5 * time.Second
+
+// statusOrder orders the overall statuses with the failing ones first
+var statusOrder = map[string]int{
+	parsers.ProviderStatusFailed:  0,
+	parsers.ProviderStatusUnknown: 1,
+	parsers.ProviderStatusSuccess: 2,
+}
+
+// cachedProjectStatus returns the cached statuses of a project for the root
+// page without fetching them, the statuses are unknown until the first
+// refresh
+func (badger *Badger) cachedProjectStatus(site *site, slug string, projectConfig ProjectConfig) ProjectStatus {
+	projectStatus := ProjectStatus{
+		Slug:   slug,
+		Config: projectConfig,
+	}
+	entry, ok := badger.statuses.peek(site.key(slug))
+	if !ok {
+		badger.Metrics.add(metricCacheRequests, 1, "status", "miss")
+		projectStatus.Overall = parsers.ProviderResult{
+			ProperName: "Overall",
+			Status:     parsers.ProviderStatusUnknown,
+			Error:      "Waiting for the first refresh",
+		}
+		projectStatus.Providers = make(map[string]parsers.ProviderResult)
+		return projectStatus
+	}
+	badger.Metrics.add(metricCacheRequests, 1, "status", "hit")
+	projectStatus.Overall = entry.overall
+	projectStatus.Providers = entry.providers
+	projectStatus.Fetched = entry.fetched
+	for _, providerStatus := range entry.providers {
+		if providerStatus.BuildDateTime.After(projectStatus.Updated) {
+			projectStatus.Updated = providerStatus.BuildDateTime
+		}
+	}
+	return projectStatus
+}
+
+// rootPageData builds the root page of a site from the cached statuses,
+// filtered, sorted and grouped by the query of the request
+func (badger *Badger) rootPageData(r *http.Request, route siteRoute) RootPageData {
+	query := r.URL.Query()
+	pageData := RootPageData{
+		BasePath:       route.basePath,
+		Projects:       badger.siteProjects(route.site),
+		Filter:         strings.TrimSpace(query.Get("filter")),
+		Status:         query.Get("status"),
+		Tag:            strings.TrimSpace(query.Get("tag")),
+		Sort:           query.Get("sort"),
+		Group:          query.Get("group"),
+		RefreshSeconds: int(badger.refreshInterval / time.Second),
+	}
+	if refresh := query.Get("refresh"); refresh != "" {
+		seconds, err := strconv.Atoi(refresh)
+		if err == nil {
+			pageData.RefreshSeconds = seconds
+		}
+	}
+	if pageData.RefreshSeconds > 0 && pageData.RefreshSeconds < int(minRootRefresh/time.Second) {
+		pageData.RefreshSeconds = int(minRootRefresh / time.Second)
+	} else if pageData.RefreshSeconds < 0 {
+		pageData.RefreshSeconds = 0
+	}
+	if pageData.Sort != RootSortStatus && pageData.Sort != RootSortUpdated {
+		pageData.Sort = RootSortName
+	}
+	if pageData.Group != RootGroupStatus && pageData.Group != RootGroupGroup {
+		pageData.Group = ""
+	}
+	pageData.Tags = siteTags(pageData.Projects)
+	for _, tag := range pageData.Tags {
+		// selects the tag on the page whatever the case of the query
+		if strings.EqualFold(tag, pageData.Tag) {
+			pageData.Tag = tag
+		}
+	}
+
+	filter := strings.ToLower(pageData.Filter)
+	projects := make([]ProjectStatus, 0, len(pageData.Projects))
+	for slug, projectConfig := range pageData.Projects {
+		if filter != "" && !strings.Contains(slug, filter) && !strings.Contains(strings.ToLower(projectConfig.Name), filter) {
+			continue
+		}
+		if pageData.Tag != "" && !hasTag(projectConfig, pageData.Tag) {
+			continue
+		}
+		projectStatus := badger.cachedProjectStatus(route.site, slug, projectConfig)
+		if pageData.Status != "" && !strings.EqualFold(projectStatus.Overall.Status, pageData.Status) {
+			continue
+		}
+		projects = append(projects, projectStatus)
+	}
+	sortProjectStatuses(projects, pageData.Sort)
+
+	switch {
+	case pageData.Group == RootGroupStatus:
+		groups := make(map[string][]ProjectStatus)
+		for _, projectStatus := range projects {
+			status := statusClass(projectStatus.Overall.Status)
+			groups[status] = append(groups[status], projectStatus)
+		}
+		for _, status := range []string{parsers.ProviderStatusFailed, parsers.ProviderStatusUnknown, parsers.ProviderStatusSuccess} {
+			if len(groups[status]) > 0 {
+				pageData.Groups = append(pageData.Groups, ProjectGroup{Name: status, Projects: groups[status]})
+			}
+		}
+	case pageData.Group == RootGroupGroup:
+		pageData.Groups = groupByProjectGroup(projects)
+	case len(projects) > 0:
+		pageData.Groups = []ProjectGroup{{Projects: projects}}
+	}
+	return pageData
+}
+
+// groupByProjectGroup groups sorted projects by their project group. The
+// groups are sorted by name with the projects without a group last.
+func groupByProjectGroup(projects []ProjectStatus) []ProjectGroup {
+	groups := make([]ProjectGroup, 0)
+	index := make(map[string]int)
+	ungrouped := make([]ProjectStatus, 0)
+	for _, projectStatus := range projects {
+		if projectStatus.Config.Group == "" {
+			ungrouped = append(ungrouped, projectStatus)
+			continue
+		}
+		slug := groupSlug(projectStatus.Config.Group)
+		i, ok := index[slug]
+		if !ok {
+			i = len(groups)
+			index[slug] = i
+			groups = append(groups, ProjectGroup{Name: strings.TrimSpace(projectStatus.Config.Group), Slug: slug})
+		}
+		groups[i].Projects = append(groups[i].Projects, projectStatus)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Slug < groups[j].Slug
+	})
+	if len(ungrouped) > 0 {
+		groups = append(groups, ProjectGroup{Name: ungroupedName, Projects: ungrouped})
+	}
+	return groups
+}
+
+// sortProjectStatuses sorts the projects by one of the RootSortXXX
+// constants, projects that are equal are sorted by name
+func sortProjectStatuses(projects []ProjectStatus, sortBy string) {
+	sort.SliceStable(projects, func(i, j int) bool {
+		first, second := projects[i], projects[j]
+		switch sortBy {
+		case RootSortStatus:
+			firstOrder := statusOrder[statusClass(first.Overall.Status)]
+			secondOrder := statusOrder[statusClass(second.Overall.Status)]
+			if firstOrder != secondOrder {
+				return firstOrder < secondOrder
+			}
+		case RootSortUpdated:
+			if !first.Updated.Equal(second.Updated) {
+				return first.Updated.After(second.Updated)
+			}
+		}
+		firstName, secondName := strings.ToLower(first.Config.Name), strings.ToLower(second.Config.Name)
+		if firstName != secondName {
+			return firstName < secondName
+		}
+		return first.Slug < second.Slug
+	})
+}
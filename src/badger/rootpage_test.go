@@ -0,0 +1,121 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	badger "."
+)
+
+func TestRootPage(t *testing.T) {
+	passing := newStatusServer(t, func(r *http.Request) {})
+	defer passing.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"build": {"status": "failed", "message": "Break the build"}}`))
+	}))
+	defer failing.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for name, statusURL := range map[string]string{"Alpha": passing.URL, "Beta": failing.URL, "Gamma": passing.URL} {
+		writeProjectFile(t, dir, strings.ToLower(name)+".bbproj", `{
+    "Name": "`+name+`",
+    "Statuses": [{"Provider": "AppVeyor", "Url": "`+statusURL+`"}]
+}`)
+	}
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: 8000},
+		Fetch:        badger.FetchConfig{Retries: -1, RefreshSeconds: 30},
+		ProjectsPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+	get := func(path string) string {
+		recorder := httptest.NewRecorder()
+		badgerBadger.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Code should be %d and not %d", http.StatusOK, recorder.Code)
+		}
+		return recorder.Body.String()
+	}
+	// projects lists the project and group headings in the order rendered
+	headings := regexp.MustCompile(`<h2 class="group">(\w+)</h2>|<a href="/\w+">(\w+)</a>`)
+	projects := func(body string) string {
+		found := make([]string, 0)
+		for _, match := range headings.FindAllStringSubmatch(body, -1) {
+			found = append(found, match[1]+match[2])
+		}
+		return strings.Join(found, ",")
+	}
+
+	t.Run("BeforeRefresh", func(t *testing.T) {
+		body := get("/")
+		if strings.Count(body, `Overall status: <span class="Unknown">Unknown</span>`) != 3 {
+			t.Errorf("Statuses should be unknown before the first refresh:\n%s", body)
+		}
+	})
+
+	badgerBadger.Refresh()
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"Name", "/", "Alpha,Beta,Gamma"},
+		{"Status", "/?sort=status", "Beta,Alpha,Gamma"},
+		{"Filter", "/?filter=MM", "Gamma"},
+		{"StatusFilter", "/?status=passing", "Alpha,Gamma"},
+		{"Group", "/?group=status", "Failing,Beta,Passing,Alpha,Gamma"},
+		{"NoMatch", "/?filter=delta", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual := projects(get(test.query))
+			if actual != test.expected {
+				t.Errorf("Projects should be '%s' and not '%s'", test.expected, actual)
+			}
+		})
+	}
+
+	t.Run("ServerSide", func(t *testing.T) {
+		body := get("/")
+		for _, expected := range []string{
+			`Overall status: <span class="Failing">Failing</span>`,
+			`AppVeyor: <span class="Failing">Failing</span>`,
+			`<noscript><meta http-equiv="refresh" content="30"></noscript>`,
+			`var refreshSeconds =`,
+		} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("Page should contain '%s':\n%s", expected, body)
+			}
+		}
+	})
+
+	t.Run("NoRefresh", func(t *testing.T) {
+		body := get("/?refresh=0")
+		if strings.Contains(body, "refreshSeconds") || strings.Contains(body, "http-equiv=\"refresh\"") {
+			t.Errorf("Page should not refresh:\n%s", body)
+		}
+	})
+}
@@ -0,0 +1,196 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// RedactedPlaceholder replaces secret values in logs and error messages
+	RedactedPlaceholder = "[REDACTED]"
+	// secretFilePrefix marks a config value that should be read from a file
+	secretFilePrefix = "file:"
+	// secretPrefix marks a config value as a secret, ie. a URL with a token
+	secretPrefix = "secret:"
+	// minSecretLength prevents short values, ie. a port, from being
+	// redacted all over the logs
+	minSecretLength = 4
+)
+
+// envReferencePattern matches ${ENV_VAR} references in config values
+var envReferencePattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// secretFields are the lower cased names of the config fields that hold
+// secrets, their values are always redacted
+var secretFields = map[string]bool{
+	"token":         true,
+	"password":      true,
+	"admintoken":    true,
+	"keyfile":       true,
+	"clientkeyfile": true,
+}
+
+// secrets holds every secret value resolved from the config files
+var secrets = struct {
+	sync.RWMutex
+	values map[string]bool
+}{values: make(map[string]bool)}
+
+// registerSecret marks value to be redacted from logs and error messages
+func registerSecret(value string) {
+	value = strings.TrimSpace(value)
+	if len(value) < minSecretLength {
+		return
+	}
+	secrets.Lock()
+	defer secrets.Unlock()
+	secrets.values[value] = true
+	// Secrets are often part of URLs, so redact the escaped form too
+	secrets.values[url.QueryEscape(value)] = true
+}
+
+// Redact replaces every known secret value in message with a placeholder
+func Redact(message string) string {
+	secrets.RLock()
+	defer secrets.RUnlock()
+	if len(secrets.values) == 0 {
+		return message
+	}
+	values := make([]string, 0, len(secrets.values))
+	for value := range secrets.values {
+		values = append(values, value)
+	}
+	// Replace the longest secrets first in case one contains another
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+	for _, value := range values {
+		message = strings.Replace(message, value, RedactedPlaceholder, -1)
+	}
+	return message
+}
+
+// redactingWriter redacts secrets from everything written to the
+// underlying writer. It is used for the log outputs.
+type redactingWriter struct {
+	writer io.Writer
+}
+
+// Write implements io.Writer
+func (redacting redactingWriter) Write(data []byte) (int, error) {
+	_, err := redacting.writer.Write([]byte(Redact(string(data))))
+	if err != nil {
+		return 0, err
+	}
+	return len(data), nil
+}
+
+// resolveReference resolves a single config value. A value starting with
+// 'file:' is replaced by the trimmed contents of that file and ${ENV_VAR}
+// references are replaced by the environment variable. File contents and
+// environment variables are always registered as secrets, as they are
+// often tokens in a URL or header. When secret is set a value without
+// references is registered as a secret too.
+func resolveReference(value string, secret bool) (string, error) {
+	if strings.HasPrefix(value, secretFilePrefix) {
+		path := strings.TrimPrefix(value, secretFilePrefix)
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("unable to read secret file '%s'", path)
+		}
+		resolved := strings.TrimRight(string(contents), "\r\n")
+		registerSecret(resolved)
+		return resolved, nil
+	}
+
+	var missing []string
+	resolved := envReferencePattern.ReplaceAllStringFunc(value, func(reference string) string {
+		name := envReferencePattern.FindStringSubmatch(reference)[1]
+		envValue, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+			return reference
+		}
+		registerSecret(envValue)
+		return envValue
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("environment variable '%s' is not set", strings.Join(missing, "', '"))
+	}
+	if secret && resolved == value {
+		// A value without references is the secret itself
+		registerSecret(resolved)
+	}
+	return resolved, nil
+}
+
+// isSecretField checks if the field path ends in one of the secretFields
+// or is a header, ie. statuses[0].headers.Authorization
+func isSecretField(path string) bool {
+	fields := strings.Split(strings.ToLower(path), ".")
+	if len(fields) > 1 && fields[len(fields)-2] == "headers" {
+		return true
+	}
+	return secretFields[fields[len(fields)-1]]
+}
+
+// resolveReferences walks a decoded config value and resolves the references
+// in every string, see resolveReference. Values of secret fields, headers
+// and values starting with 'secret:' are registered as secrets. The
+// returned error contains the field path of the offending value.
+func resolveReferences(value interface{}, path string) (interface{}, *ValidationError) {
+	switch typed := value.(type) {
+	case string:
+		secret := isSecretField(path)
+		if strings.HasPrefix(typed, secretPrefix) {
+			typed = strings.TrimPrefix(typed, secretPrefix)
+			secret = true
+		}
+		resolved, err := resolveReference(typed, secret)
+		if err != nil {
+			return nil, &ValidationError{Field: path, Message: err.Error()}
+		}
+		return resolved, nil
+	case map[string]interface{}:
+		for key, child := range typed {
+			field := key
+			if path != "" {
+				field = path + "." + key
+			}
+			resolved, validationError := resolveReferences(child, field)
+			if validationError != nil {
+				return nil, validationError
+			}
+			typed[key] = resolved
+		}
+	case []interface{}:
+		for i, child := range typed {
+			resolved, validationError := resolveReferences(child, fmt.Sprintf("%s[%d]", path, i))
+			if validationError != nil {
+				return nil, validationError
+			}
+			typed[i] = resolved
+		}
+	}
+	return value, nil
+}
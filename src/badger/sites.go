@@ -0,0 +1,261 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// site is a set of projects served on some host names and base paths. The
+// default site serves every host name not claimed by a virtual host.
+type site struct {
+	// name is blank for the default site
+	name      string
+	hosts     []string
+	basePaths []string
+	// excludedHosts are the host names of the virtual hosts, only used by
+	// the default site
+	excludedHosts []string
+	projectsPath  string
+	// pagesPath overrides files of Badger.PagesPath, blank for none
+	pagesPath string
+	// templates, projects, aliases and projectFiles are guarded by
+	// Badger.projectsLock
+	templates *templateSet
+	projects  map[string]ProjectConfig
+	// aliases maps the aliases to the project slugs
+	aliases      map[string]string
+	projectFiles map[string]ProjectConfig
+}
+
+// siteRoute is the site and base path a request was routed to
+type siteRoute struct {
+	site     *site
+	basePath string
+}
+
+// siteContextKey keeps the siteRoute in the request context
+type siteContextKey struct{}
+
+// newSites creates the default site and one site per virtual host, with
+// the virtual hosts first so that they are routed first
+func newSites(config Config, projectsPath string) ([]*site, error) {
+	sites := make([]*site, 0, len(config.Server.VirtualHosts)+1)
+	excludedHosts := make([]string, 0)
+	names := make(map[string]bool)
+	for _, virtualHost := range config.Server.VirtualHosts {
+		name := virtualHost.Name
+		if name == "" && len(virtualHost.Hosts) > 0 {
+			name = virtualHost.Hosts[0]
+		}
+		if name == "" {
+			return nil, errors.New("Virtual hosts without host names need a name")
+		}
+		if names[name] {
+			return nil, errors.New("Virtual host '" + name + "' is configured twice")
+		}
+		names[name] = true
+		if virtualHost.ProjectsPath == "" {
+			return nil, errors.New("Virtual host '" + name + "' needs a projects path")
+		}
+		virtualProjectsPath, err := filepath.Abs(virtualHost.ProjectsPath)
+		if err != nil {
+			return nil, errors.New("Unable to get project path: " + err.Error())
+		}
+		basePaths := virtualHost.BasePaths
+		if len(basePaths) == 0 {
+			basePaths = []string{""}
+		}
+		sites = append(sites, &site{
+			name:         name,
+			hosts:        virtualHost.Hosts,
+			basePaths:    cleanBasePaths(basePaths),
+			projectsPath: virtualProjectsPath,
+			pagesPath:    virtualHost.PagesPath,
+			projects:     make(map[string]ProjectConfig),
+			aliases:      make(map[string]string),
+			projectFiles: make(map[string]ProjectConfig),
+		})
+		excludedHosts = append(excludedHosts, virtualHost.Hosts...)
+	}
+	sites = append(sites, &site{
+		basePaths:     cleanBasePaths(append([]string{config.Server.BasePath}, config.Server.BasePaths...)),
+		excludedHosts: excludedHosts,
+		projectsPath:  projectsPath,
+		projects:      make(map[string]ProjectConfig),
+		aliases:       make(map[string]string),
+		projectFiles:  make(map[string]ProjectConfig),
+	})
+	return sites, nil
+}
+
+// cleanBasePaths removes trailing slashes and duplicates
+func cleanBasePaths(basePaths []string) []string {
+	cleaned := make([]string, 0, len(basePaths))
+	seen := make(map[string]bool)
+	for _, basePath := range basePaths {
+		basePath = strings.TrimSuffix(basePath, "/")
+		if basePath != "" && !strings.HasPrefix(basePath, "/") {
+			basePath = "/" + basePath
+		}
+		if !seen[basePath] {
+			seen[basePath] = true
+			cleaned = append(cleaned, basePath)
+		}
+	}
+	return cleaned
+}
+
+// matches checks if the site serves the host of a request
+func (site *site) matches(r *http.Request, match *mux.RouteMatch) bool {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	if len(site.hosts) > 0 {
+		return containsFold(site.hosts, host)
+	}
+	return !containsFold(site.excludedHosts, host)
+}
+
+// containsFold checks if values contains value ignoring case
+func containsFold(values []string, value string) bool {
+	for _, candidate := range values {
+		if strings.EqualFold(candidate, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// key qualifies a project slug with the site name so that projects of
+// different sites don't share cached statuses and metrics
+func (site *site) key(slug string) string {
+	if site.name == "" {
+		return slug
+	}
+	return site.name + "/" + slug
+}
+
+// label is the site name for log messages
+func (site *site) label() string {
+	if site.name == "" {
+		return "default"
+	}
+	return site.name
+}
+
+// pagesFor returns the page templates and assets of a site, the files in
+// the site's pages path override the default pages
+func (badger *Badger) pagesFor(site *site) fs.FS {
+	if site.pagesPath != "" {
+		return overlayFS{dir: site.pagesPath, fallback: badger.pages()}
+	}
+	return badger.pages()
+}
+
+// routeFor returns the site and base path the request was routed to, the
+// default site for requests that weren't routed to a site
+func (badger *Badger) routeFor(r *http.Request) siteRoute {
+	if route, ok := r.Context().Value(siteContextKey{}).(siteRoute); ok {
+		return route
+	}
+	defaultSite := badger.defaultSite()
+	return siteRoute{site: defaultSite, basePath: defaultSite.basePaths[0]}
+}
+
+// defaultSite returns the site that serves all the other host names
+func (badger *Badger) defaultSite() *site {
+	return badger.sites[len(badger.sites)-1]
+}
+
+// siteProject returns the config of a project on a site
+func (badger *Badger) siteProject(site *site, slug string) (ProjectConfig, bool) {
+	badger.projectsLock.RLock()
+	defer badger.projectsLock.RUnlock()
+	projectConfig, ok := site.projects[slug]
+	return projectConfig, ok
+}
+
+// siteProjects returns a copy of the projects of a site keyed by slug
+func (badger *Badger) siteProjects(site *site) map[string]ProjectConfig {
+	badger.projectsLock.RLock()
+	defer badger.projectsLock.RUnlock()
+	projects := make(map[string]ProjectConfig, len(site.projects))
+	for slug, projectConfig := range site.projects {
+		projects[slug] = projectConfig
+	}
+	return projects
+}
+
+// withRoute adds the site route to the request context
+func withRoute(route siteRoute, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), siteContextKey{}, route)
+		handler.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// assetHandler serves the files in a directory of the site's pages
+func (badger *Badger) assetHandler(site *site, directory string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assets, err := fs.Sub(badger.pagesFor(site), directory)
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		http.FileServer(http.FS(assets)).ServeHTTP(w, r)
+	})
+}
+
+// routeSites adds the page, badge, status, event and asset routes of every
+// site on every base path
+func (badger *Badger) routeSites(router *mux.Router) {
+	for _, site := range badger.sites {
+		siteRouter := router.MatcherFunc(site.matches).Subrouter()
+		for _, basePath := range site.basePaths {
+			route := siteRoute{site: site, basePath: basePath}
+			handle := func(path string, handler http.Handler) {
+				siteRouter.Handle(basePath+path, badger.instrument(path, withRoute(route, handler)))
+			}
+			handle("/", http.HandlerFunc(badger.RootHandler))
+			// before /{project} so that it isn't taken as a project
+			handle("/events", http.HandlerFunc(badger.EventsHandler))
+			handle("/groups/{group}", http.HandlerFunc(badger.GroupPageHandler))
+			handle("/groups/{group}/badge", http.HandlerFunc(badger.GroupBadgeHandler))
+			handle("/{project}", badger.redirectAliases(http.HandlerFunc(badger.ProjectPageHandler)))
+			handle("/{project}/events", badger.redirectAliases(http.HandlerFunc(badger.ProjectEventsHandler)))
+			handle("/{project}/badge", badger.redirectAliases(http.HandlerFunc(badger.ProjectBadgeHandler)))
+			handle("/{project}/status", badger.redirectAliases(http.HandlerFunc(badger.ProjectStatusHandler)))
+			// before /{project}/{type}/badge so that /{project}/badge/badge
+			// is a variant
+			handle("/{project}/badge/{variant}", badger.redirectAliases(http.HandlerFunc(badger.ProjectBadgeHandler)))
+			handle("/{project}/{type}/badge", badger.redirectAliases(http.HandlerFunc(badger.ValueBadgeHandler)))
+			// serve the CSS, JS and image files directly
+			for _, directory := range []string{"css", "js", "i"} {
+				prefix := basePath + "/" + directory + "/"
+				assets := http.StripPrefix(prefix, badger.assetHandler(site, directory))
+				siteRouter.PathPrefix(prefix).Handler(badger.instrument("/"+directory+"/", assets))
+			}
+		}
+	}
+}
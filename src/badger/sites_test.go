@@ -0,0 +1,122 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	badger "."
+)
+
+func TestSites(t *testing.T) {
+	provider := newStatusServer(t, func(r *http.Request) {})
+	defer provider.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defaultDir := filepath.Join(dir, "default")
+	otherDir := filepath.Join(dir, "other")
+	os.Mkdir(defaultDir, 0755)
+	os.Mkdir(otherDir, 0755)
+	writeProjectFile(t, defaultDir, "sample.bbproj", `{
+    "Name": "Sample",
+    "Statuses": [{"Provider": "AppVeyor", "Url": "`+provider.URL+`"}]
+}`)
+	writeProjectFile(t, otherDir, "other.bbproj", `{
+    "Name": "Other",
+    "Statuses": [{"Provider": "AppVeyor", "Url": "`+provider.URL+`"}]
+}`)
+
+	badgerBadger, err := badger.New(badger.Config{
+		Server: badger.ServerConfig{
+			IP:        "127.0.0.1",
+			Port:      8000,
+			BasePath:  "/status",
+			BasePaths: []string{"/badger/"},
+			VirtualHosts: []badger.VirtualHostConfig{{
+				Hosts:        []string{"other.example.com"},
+				ProjectsPath: otherDir,
+			}},
+		},
+		Fetch:        badger.FetchConfig{Retries: -1},
+		ProjectsPath: defaultDir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+
+	get := func(url string) (int, string) {
+		recorder := httptest.NewRecorder()
+		badgerBadger.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", url, nil))
+		return recorder.Code, recorder.Body.String()
+	}
+
+	tests := []struct {
+		name     string
+		url      string
+		code     int
+		contains string
+		excludes string
+	}{
+		{"BasePath", "http://badger.example.com/status/", http.StatusOK, `href="/status/css/page.css"`, "Other"},
+		{"BasePathScript", "http://badger.example.com/status/", http.StatusOK, `var baseUrl = "/status/"`, ""},
+		{"ExtraBasePath", "http://badger.example.com/badger/", http.StatusOK, `href="/badger/css/page.css"`, ""},
+		{"ExtraBasePathStatus", "http://badger.example.com/badger/sample/status", http.StatusOK, "Passing", ""},
+		{"Assets", "http://badger.example.com/status/css/page.css", http.StatusOK, "div.holder", ""},
+		{"NotOnRoot", "http://badger.example.com/sample/status", http.StatusNotFound, "", ""},
+		{"VirtualHost", "http://other.example.com:8000/", http.StatusOK, `href="/css/page.css"`, "Sample"},
+		{"VirtualHostStatus", "http://OTHER.example.com/other/status", http.StatusOK, "Passing", ""},
+		{"VirtualHostProjects", "http://other.example.com/sample/status", http.StatusNotFound, "", ""},
+		{"DefaultProjects", "http://badger.example.com/status/other/status", http.StatusNotFound, "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, body := get(test.url)
+			if code != test.code {
+				t.Errorf("Code should be %d and not %d", test.code, code)
+			}
+			if !strings.Contains(body, test.contains) {
+				t.Errorf("Body should contain '%s':\n%s", test.contains, body)
+			}
+			if test.excludes != "" && strings.Contains(body, test.excludes) {
+				t.Errorf("Body should not contain '%s':\n%s", test.excludes, body)
+			}
+		})
+	}
+
+	t.Run("Reload", func(t *testing.T) {
+		writeProjectFile(t, otherDir, "second.bbproj", `{
+    "Name": "Second",
+    "Statuses": [{"Provider": "AppVeyor", "Url": "`+provider.URL+`"}]
+}`)
+		result, err := badgerBadger.Reload()
+		if err != nil {
+			t.Fatalf("Unable to reload: %s", err.Error())
+		}
+		if len(result.Added) != 1 || result.Added[0] != "other.example.com/second" {
+			t.Errorf("Added should be '%s' and not '%v'", "other.example.com/second", result.Added)
+		}
+		if _, ok := badgerBadger.Project("second"); ok {
+			t.Errorf("Virtual host projects should not be on the default site")
+		}
+	})
+}
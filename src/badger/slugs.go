@@ -0,0 +1,196 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/gorilla/mux"
+)
+
+// slugPattern matches the slugs and aliases that can be set in the project
+// files, ie. 'io-rpc'
+var slugPattern = regexp.MustCompile(`^[\p{Ll}\p{Lo}\p{N}]+([-_.][\p{Ll}\p{Lo}\p{N}]+)*$`)
+
+// reservedSlugs are the first path segments Badger routes itself
+var reservedSlugs = map[string]bool{
+	"events":  true,
+	"groups":  true,
+	"css":     true,
+	"js":      true,
+	"i":       true,
+	"healthz": true,
+	"readyz":  true,
+	"metrics": true,
+	"admin":   true,
+}
+
+// Slugify turns a project name into a slug of lowercase letters and digits
+// separated by dashes, ie. 'Badger: The Server' becomes 'badger-the-server'
+func Slugify(name string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if dash && slug.Len() > 0 {
+				slug.WriteRune('-')
+			}
+			slug.WriteRune(r)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return slug.String()
+}
+
+// projectSlug creates the URL key for a project, the Slug if it is set and
+// otherwise the slugified name
+func projectSlug(projectConfig ProjectConfig) string {
+	if projectConfig.Slug != "" {
+		return projectConfig.Slug
+	}
+	return Slugify(projectConfig.Name)
+}
+
+// legacySlug is the slug earlier versions generated from the name. It
+// redirects to the slug so that existing badge URLs keep working.
+func legacySlug(projectConfig ProjectConfig) string {
+	return strings.Replace(strings.ToLower(projectConfig.Name), " ", "-", -1)
+}
+
+// validateSlug checks a slug or alias set in a project file
+func validateSlug(slug string) string {
+	if !slugPattern.MatchString(slug) {
+		return "must be lowercase letters and digits separated by '-', '_' or '.'"
+	}
+	if reservedSlugs[slug] {
+		return fmt.Sprintf("'%s' is reserved by Badger", slug)
+	}
+	return ""
+}
+
+// indexProjects keys the loaded project files by slug and maps the aliases
+// to the slugs. The slugs are claimed before the aliases, in file name
+// order, and files that claim a taken slug or alias are returned as
+// conflicts with the reason instead of silently replacing a project.
+func indexProjects(loaded map[string]ProjectConfig) (map[string]ProjectConfig, map[string]string, map[string]string) {
+	fileNames := make([]string, 0, len(loaded))
+	for fileName := range loaded {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	conflicts := make(map[string]string)
+	owners := make(map[string]string)
+	for _, fileName := range fileNames {
+		slug := projectSlug(loaded[fileName])
+		if owner, taken := owners[slug]; taken {
+			conflicts[fileName] = fmt.Sprintf("Project slug '%s' of '%s' is already used by '%s'", slug, fileName, owner)
+			continue
+		}
+		owners[slug] = fileName
+	}
+
+	aliases := make(map[string]string)
+	aliasOwners := make(map[string]string)
+	for _, fileName := range fileNames {
+		if _, conflict := conflicts[fileName]; conflict {
+			continue
+		}
+		projectConfig := loaded[fileName]
+		slug := projectSlug(projectConfig)
+		for _, alias := range projectConfig.Aliases {
+			owner, taken := owners[alias]
+			if !taken {
+				owner, taken = aliasOwners[alias]
+			}
+			if taken && owner != fileName {
+				conflicts[fileName] = fmt.Sprintf("Project alias '%s' of '%s' is already used by '%s'", alias, fileName, owner)
+				break
+			}
+		}
+		if _, conflict := conflicts[fileName]; conflict {
+			delete(owners, slug)
+			continue
+		}
+		for _, alias := range projectConfig.Aliases {
+			aliases[alias] = slug
+			aliasOwners[alias] = fileName
+		}
+	}
+
+	projects := make(map[string]ProjectConfig, len(owners))
+	for slug, fileName := range owners {
+		projects[slug] = loaded[fileName]
+	}
+	// The legacy slugs only redirect when nothing else claims them
+	for slug, projectConfig := range projects {
+		legacy := legacySlug(projectConfig)
+		if legacy == slug || legacy == "" || strings.Contains(legacy, "/") {
+			continue
+		}
+		if _, taken := projects[legacy]; taken {
+			continue
+		}
+		if _, taken := aliases[legacy]; taken {
+			continue
+		}
+		aliases[legacy] = slug
+	}
+	return projects, aliases, conflicts
+}
+
+// siteAlias returns the slug of the project an alias redirects to
+func (badger *Badger) siteAlias(site *site, alias string) (string, bool) {
+	badger.projectsLock.RLock()
+	defer badger.projectsLock.RUnlock()
+	if _, ok := site.projects[alias]; ok {
+		return "", false
+	}
+	slug, ok := site.aliases[alias]
+	return slug, ok
+}
+
+// redirectAliases permanently redirects the requests for an alias of a
+// project to the same path with the project's slug, ie. /old-name/badge to
+// /new-name/badge
+func (badger *Badger) redirectAliases(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := badger.routeFor(r)
+		alias := strings.ToLower(mux.Vars(r)["project"])
+		slug, ok := badger.siteAlias(route.site, alias)
+		if !ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+		rest := strings.TrimPrefix(r.URL.Path, route.basePath+"/")
+		if index := strings.Index(rest, "/"); index >= 0 {
+			rest = rest[index:]
+		} else {
+			rest = ""
+		}
+		target := joinURL(route.basePath, slug) + rest
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		badger.log.Debug("Redirecting alias '%s' to project '%s'", alias, slug)
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+}
@@ -0,0 +1,137 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	badger "."
+)
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Badger: The Server": "badger-the-server",
+		"  ioRPC  ":          "iorpc",
+		"C++ Tools v2.1":     "c-tools-v2-1",
+		"Ünïcode Näme":       "ünïcode-näme",
+		"!!!":                "",
+	}
+	for name, expected := range tests {
+		if actual := badger.Slugify(name); actual != expected {
+			t.Errorf("Slug of '%s' should be '%s' and not '%s'", name, expected, actual)
+		}
+	}
+}
+
+func TestSlugs(t *testing.T) {
+	provider := newStatusServer(t, func(r *http.Request) {})
+	defer provider.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	statuses := `"Statuses": [{"Provider": "AppVeyor", "Url": "` + provider.URL + `"}]`
+	writeProjectFile(t, dir, "a.bbproj", `{"Name": "io.RPC Server", "Slug": "iorpc", "Aliases": ["old-iorpc"], `+statuses+`}`)
+	writeProjectFile(t, dir, "b.bbproj", `{"Name": "Foo.Bar", `+statuses+`}`)
+	// Conflicts with the slug of a.bbproj and the alias of b.bbproj
+	writeProjectFile(t, dir, "c.bbproj", `{"Name": "Other", "Slug": "iorpc", `+statuses+`}`)
+	writeProjectFile(t, dir, "d.bbproj", `{"Name": "Delta", "Aliases": ["old-iorpc"], `+statuses+`}`)
+
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: 8000, BasePath: "/status"},
+		Fetch:        badger.FetchConfig{Retries: -1},
+		ProjectsPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+
+	t.Run("Conflicts", func(t *testing.T) {
+		slugs := make([]string, 0)
+		for slug := range badgerBadger.Projects() {
+			slugs = append(slugs, slug)
+		}
+		sort.Strings(slugs)
+		if strings.Join(slugs, ",") != "foo-bar,iorpc" {
+			t.Errorf("Projects should be '%s' and not '%s'", "foo-bar,iorpc", strings.Join(slugs, ","))
+		}
+		projectConfig, _ := badgerBadger.Project("iorpc")
+		if projectConfig.Name != "io.RPC Server" {
+			t.Errorf("The first file should keep the slug, got '%s'", projectConfig.Name)
+		}
+		result, err := badgerBadger.Reload()
+		if err != nil {
+			t.Fatalf("Unable to reload: %s", err.Error())
+		}
+		if _, ok := result.Failed["c.bbproj"]; !ok {
+			t.Errorf("The slug conflict should be reported, got %v", result.Failed)
+		}
+		if _, ok := result.Failed["d.bbproj"]; !ok {
+			t.Errorf("The alias conflict should be reported, got %v", result.Failed)
+		}
+	})
+
+	t.Run("Redirects", func(t *testing.T) {
+		tests := map[string]string{
+			"/status/old-iorpc":                "/status/iorpc",
+			"/status/Old-IoRPC/badge?v=2":      "/status/iorpc/badge?v=2",
+			"/status/old-iorpc/coverage/badge": "/status/iorpc/coverage/badge",
+			// The slug of earlier versions
+			"/status/foo.bar/status": "/status/foo-bar/status",
+		}
+		for path, expected := range tests {
+			recorder := httptest.NewRecorder()
+			badgerBadger.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+			if recorder.Code != http.StatusMovedPermanently {
+				t.Errorf("Code of '%s' should be %d and not %d", path, http.StatusMovedPermanently, recorder.Code)
+			}
+			if recorder.Header().Get("Location") != expected {
+				t.Errorf("'%s' should redirect to '%s' and not '%s'", path, expected, recorder.Header().Get("Location"))
+			}
+		}
+		recorder := httptest.NewRecorder()
+		badgerBadger.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/status/iorpc", nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Code should be %d and not %d", http.StatusOK, recorder.Code)
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		tests := []struct {
+			projectConfig badger.ProjectConfig
+			field         string
+		}{
+			{badger.ProjectConfig{Name: "Sample", Slug: "Bad Slug"}, "Slug"},
+			{badger.ProjectConfig{Name: "Sample", Slug: "events"}, "Slug"},
+			{badger.ProjectConfig{Name: "Metrics"}, "Name"},
+			{badger.ProjectConfig{Name: "!!!"}, "Name"},
+			{badger.ProjectConfig{Name: "Sample", Aliases: []string{"sample"}}, "Aliases[0]"},
+			{badger.ProjectConfig{Name: "Sample", Aliases: []string{"old", "old"}}, "Aliases[1]"},
+		}
+		for _, test := range tests {
+			test.projectConfig.Statuses = []badger.StatusConfig{{Provider: "AppVeyor", URL: "https://ci.appveyor.com"}}
+			validationErrors := badger.ValidateProject(test.projectConfig)
+			if len(validationErrors) != 1 || validationErrors[0].Field != test.field {
+				t.Errorf("%+v should be invalid on '%s', got %v", test.projectConfig, test.field, validationErrors)
+			}
+		}
+	})
+}
@@ -0,0 +1,205 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"./parsers"
+)
+
+// defaultRefreshInterval is how often the statuses are refreshed when no
+// interval is configured
+const defaultRefreshInterval = 60 * time.Second
+
+// cachedStatuses are the statuses of a project at the time they were fetched
+type cachedStatuses struct {
+	overall   parsers.ProviderResult
+	providers map[string]parsers.ProviderResult
+	fetched   time.Time
+}
+
+// statusCache keeps the last fetched statuses per site qualified project
+// slug
+type statusCache struct {
+	sync.RWMutex
+	entries map[string]cachedStatuses
+	// refreshed is when the last refresh of all projects completed
+	refreshed time.Time
+}
+
+// newStatusCache creates an empty status cache
+func newStatusCache() *statusCache {
+	return &statusCache{
+		entries: make(map[string]cachedStatuses),
+	}
+}
+
+// get returns the statuses of project if they are younger than maxAge
+func (cache *statusCache) get(project string, maxAge time.Duration) (cachedStatuses, bool) {
+	cache.RLock()
+	defer cache.RUnlock()
+	entry, ok := cache.entries[project]
+	if !ok || time.Since(entry.fetched) > maxAge {
+		return entry, false
+	}
+	return entry, true
+}
+
+// peek returns the statuses of project regardless of their age
+func (cache *statusCache) peek(project string) (cachedStatuses, bool) {
+	cache.RLock()
+	defer cache.RUnlock()
+	entry, ok := cache.entries[project]
+	return entry, ok
+}
+
+// store caches the statuses of project and returns them with the
+// previously cached statuses, if any
+func (cache *statusCache) store(project string, overall parsers.ProviderResult, providers map[string]parsers.ProviderResult) (cachedStatuses, cachedStatuses, bool) {
+	entry := cachedStatuses{
+		overall:   overall,
+		providers: providers,
+		fetched:   time.Now(),
+	}
+	cache.Lock()
+	defer cache.Unlock()
+	previous, ok := cache.entries[project]
+	cache.entries[project] = entry
+	return entry, previous, ok
+}
+
+// forget removes the statuses of project and its branches, ie. when its
+// config changed
+func (cache *statusCache) forget(project string) {
+	cache.Lock()
+	defer cache.Unlock()
+	delete(cache.entries, project)
+	for key := range cache.entries {
+		if strings.HasPrefix(key, project+branchSeparator) {
+			delete(cache.entries, key)
+		}
+	}
+}
+
+// prune removes the branch statuses older than maxAge so that requests for
+// branches that aren't tracked don't grow the cache forever
+func (cache *statusCache) prune(maxAge time.Duration) {
+	cache.Lock()
+	defer cache.Unlock()
+	for key, entry := range cache.entries {
+		if strings.Contains(key, branchSeparator) && time.Since(entry.fetched) > maxAge {
+			delete(cache.entries, key)
+		}
+	}
+}
+
+// markRefreshed records that all the projects were refreshed
+func (cache *statusCache) markRefreshed() {
+	cache.Lock()
+	defer cache.Unlock()
+	cache.refreshed = time.Now()
+}
+
+// lastRefresh returns when the last refresh of all projects completed, zero
+// if there hasn't been one
+func (cache *statusCache) lastRefresh() time.Time {
+	cache.RLock()
+	defer cache.RUnlock()
+	return cache.refreshed
+}
+
+// refreshProject fetches and caches the statuses of a project and
+// publishes the provider statuses that changed
+func (badger *Badger) refreshProject(site *site, project string, projectConfig ProjectConfig) cachedStatuses {
+	key := site.key(project)
+	overallStatus, providerStatuses := badger.Fetcher.FetchAllStatuses(branchStatuses(projectConfig, ""))
+	badger.Metrics.recordStatuses(key, overallStatus, providerStatuses)
+	entry, previous, ok := badger.statuses.store(key, overallStatus, providerStatuses)
+	badger.publishChanges(site, project, entry, previous, ok)
+	return entry
+}
+
+// refreshBranch fetches and caches the statuses of a branch of a project.
+// Only the default statuses are published and recorded in the metrics.
+func (badger *Badger) refreshBranch(site *site, project string, projectConfig ProjectConfig, branch string) cachedStatuses {
+	if branch == "" {
+		return badger.refreshProject(site, project, projectConfig)
+	}
+	overallStatus, providerStatuses := badger.Fetcher.FetchAllStatuses(branchStatuses(projectConfig, branch))
+	entry, _, _ := badger.statuses.store(branchKey(site, project, branch), overallStatus, providerStatuses)
+	return entry
+}
+
+// fetchProjectStatuses returns the cached statuses of a project. They are
+// fetched if the cache has none or the refresh has fallen behind.
+func (badger *Badger) fetchProjectStatuses(site *site, project string, projectConfig ProjectConfig) (parsers.ProviderResult, map[string]parsers.ProviderResult) {
+	return badger.fetchBranchStatuses(site, project, projectConfig, "")
+}
+
+// fetchBranchStatuses returns the cached statuses of a branch of a project,
+// the default statuses if branch is blank. They are fetched if the cache
+// has none or they are older than two refresh intervals.
+func (badger *Badger) fetchBranchStatuses(site *site, project string, projectConfig ProjectConfig, branch string) (parsers.ProviderResult, map[string]parsers.ProviderResult) {
+	entry, ok := badger.statuses.get(branchKey(site, project, branch), 2*badger.refreshInterval)
+	if ok {
+		badger.Metrics.add(metricCacheRequests, 1, "status", "hit")
+	} else {
+		badger.Metrics.add(metricCacheRequests, 1, "status", "miss")
+		entry = badger.refreshBranch(site, project, projectConfig, branch)
+	}
+	return entry.overall, entry.providers
+}
+
+// Refresh fetches the statuses of the projects of all sites into the cache
+func (badger *Badger) Refresh() {
+	badger.refresh(context.Background())
+}
+
+// refresh fetches the statuses of all the projects, stopping early when
+// ctx is done
+func (badger *Badger) refresh(ctx context.Context) {
+	for _, site := range badger.sites {
+		for project, projectConfig := range badger.siteProjects(site) {
+			if ctx.Err() != nil {
+				return
+			}
+			badger.refreshProject(site, project, projectConfig)
+			for _, branch := range projectConfig.Branches {
+				badger.refreshBranch(site, project, projectConfig, branch)
+			}
+		}
+	}
+	badger.statuses.prune(2 * badger.refreshInterval)
+	badger.statuses.markRefreshed()
+	badger.log.Debug("Statuses refreshed")
+}
+
+// poll refreshes the statuses every refresh interval until ctx is done
+func (badger *Badger) poll(ctx context.Context) {
+	badger.refresh(ctx)
+	ticker := time.NewTicker(badger.refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			badger.refresh(ctx)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"./parsers"
+)
+
+// statusTypes are the status types each provider reports, the first is
+// the type of statuses without a Type
+var statusTypes = map[string][]string{
+	ProviderAppveyor:  {StatusTypeBuild, StatusTypeTests},
+	ProviderTravisCI:  {StatusTypeBuild},
+	ProviderCoveralls: {StatusTypeCoverage},
+	ProviderCodecov:   {StatusTypeCoverage},
+	ProviderGitHub:    {StatusTypeRelease},
+}
+
+// defaultThresholds are the thresholds of the status types with a value
+// when the status doesn't set any
+var defaultThresholds = map[string][]ThresholdConfig{
+	StatusTypeCoverage: {
+		{Below: 60, Color: "red", Failing: true},
+		{Below: 80, Color: "yellow"},
+	},
+	StatusTypeTests: {
+		{Below: 100, Color: "red", Failing: true},
+	},
+}
+
+// badgeColors are the named colours of the value badges
+var badgeColors = map[string]string{
+	"brightgreen": "#4c1",
+	"green":       "#97ca00",
+	"yellowgreen": "#a4a61d",
+	"yellow":      "#dfb317",
+	"orange":      "#fe7d37",
+	"red":         "#e05d44",
+	"blue":        "#007ec6",
+	"lightgrey":   "#9f9f9f",
+}
+
+// hexColorPattern matches the hex colours of the value badges
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// statusType returns the type of a status in lowercase
+func statusType(status StatusConfig) string {
+	if status.Type != "" {
+		return strings.ToLower(status.Type)
+	}
+	if types, ok := statusTypes[strings.ToLower(status.Provider)]; ok {
+		return types[0]
+	}
+	return StatusTypeBuild
+}
+
+// statusKey returns the key of a status in the provider results of a
+// project. It is the provider for the provider's default type so that a
+// provider can report several types, ie. 'appveyor' and 'appveyor-tests'.
+func statusKey(status StatusConfig) string {
+	provider := strings.ToLower(status.Provider)
+	typeName := statusType(status)
+	if types, ok := statusTypes[provider]; !ok || types[0] == typeName {
+		return provider
+	}
+	return provider + "-" + typeName
+}
+
+// supportsType checks if a provider reports a status type
+func supportsType(provider string, statusType string) bool {
+	for _, candidate := range statusTypes[strings.ToLower(provider)] {
+		if candidate == statusType {
+			return true
+		}
+	}
+	return false
+}
+
+// thresholds returns the thresholds of a status sorted from the lowest
+func thresholds(status StatusConfig) []ThresholdConfig {
+	configured := status.Thresholds
+	if len(configured) == 0 {
+		configured = defaultThresholds[statusType(status)]
+	}
+	sorted := make([]ThresholdConfig, len(configured))
+	copy(sorted, configured)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Below < sorted[j].Below
+	})
+	return sorted
+}
+
+// thresholdFor returns the lowest threshold a value is below
+func thresholdFor(status StatusConfig, value float64) (ThresholdConfig, bool) {
+	for _, threshold := range thresholds(status) {
+		if value < threshold.Below {
+			return threshold, true
+		}
+	}
+	return ThresholdConfig{}, false
+}
+
+// typedValue returns the value the thresholds of a status apply to, the
+// covered percentage or the percentage of the tests that ran that passed
+func typedValue(result parsers.ProviderResult) float64 {
+	switch result.Type {
+	case StatusTypeCoverage:
+		return result.Coverage
+	case StatusTypeTests:
+		ran := result.TestsPassed + result.TestsFailed
+		if ran == 0 {
+			return 0
+		}
+		return float64(result.TestsPassed) * 100 / float64(ran)
+	}
+	return 0
+}
+
+// formatPercent formats a percentage with at most one decimal, ie. 87.5%
+func formatPercent(value float64) string {
+	return strconv.FormatFloat(float64(int(value*10+0.5))/10, 'f', -1, 64) + "%"
+}
+
+// applyStatusType sets the type and value of a parsed result. The status
+// of coverage and tests results comes from the thresholds.
+func applyStatusType(status StatusConfig, result *parsers.ProviderResult) {
+	result.Type = statusType(status)
+	switch result.Type {
+	case StatusTypeTests:
+		if result.TestsPassed+result.TestsFailed == 0 {
+			result.Status = parsers.ProviderStatusUnknown
+			result.IsSuccess = false
+			result.Value = "no tests"
+			return
+		}
+		result.Value = fmt.Sprintf("%d passed, %d failed", result.TestsPassed, result.TestsFailed)
+		applyThresholds(status, result)
+	case StatusTypeCoverage:
+		result.Value = formatPercent(result.Coverage)
+		applyThresholds(status, result)
+	case StatusTypeRelease:
+		result.Value = result.Version
+	default:
+		result.Value = strings.ToLower(result.Status)
+	}
+}
+
+// applyThresholds fails a result when its value is below a failing
+// threshold
+func applyThresholds(status StatusConfig, result *parsers.ProviderResult) {
+	result.Status = parsers.ProviderStatusSuccess
+	result.IsSuccess = true
+	if threshold, ok := thresholdFor(status, typedValue(*result)); ok && threshold.Failing {
+		result.Status = parsers.ProviderStatusFailed
+		result.IsSuccess = false
+	}
+}
+
+// valueColor returns the hex colour of the value badge of a result
+func valueColor(status StatusConfig, result parsers.ProviderResult) string {
+	color := "brightgreen"
+	switch {
+	case result.Status == parsers.ProviderStatusUnknown || result.Status == "":
+		color = "lightgrey"
+	case result.Type == StatusTypeCoverage || result.Type == StatusTypeTests:
+		if threshold, ok := thresholdFor(status, typedValue(result)); ok {
+			color = threshold.Color
+		}
+	case result.Type == StatusTypeRelease:
+		color = "blue"
+	case result.Status == parsers.ProviderStatusFailed:
+		color = "red"
+	}
+	if hex, ok := badgeColors[strings.ToLower(color)]; ok {
+		return hex
+	}
+	return color
+}
+
+// validColor checks if a colour is a named colour or a hex colour
+func validColor(color string) bool {
+	_, ok := badgeColors[strings.ToLower(color)]
+	return ok || hexColorPattern.MatchString(color)
+}
@@ -0,0 +1,161 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	badger "."
+)
+
+func TestStatusTypes(t *testing.T) {
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/appveyor":
+			w.Write([]byte(`{"build": {"status": "success", "message": "Add tests", "jobs": [
+				{"status": "success", "testsCount": 12, "passedTestsCount": 10, "failedTestsCount": 0},
+				{"status": "success", "testsCount": 8, "passedTestsCount": 7, "failedTestsCount": 1}]}}`))
+		case "/coveralls":
+			w.Write([]byte(`{"covered_percent": 55.46, "commit_message": "Add tests"}`))
+		case "/releases":
+			w.Write([]byte(`[{"tag_name": "v1.2.0", "name": "Release"}]`))
+		}
+	}))
+	defer provider.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeProjectFile(t, dir, "sample.bbproj", `{
+    "Name": "Sample",
+    "Statuses": [
+        {"Type": "Build", "Provider": "AppVeyor", "Url": "`+provider.URL+`/appveyor"},
+        {"Type": "Tests", "Provider": "AppVeyor", "Url": "`+provider.URL+`/appveyor"},
+        {"Provider": "Coveralls", "Url": "`+provider.URL+`/coveralls"},
+        {"Type": "Release", "Provider": "GitHub", "Url": "`+provider.URL+`/releases"}
+    ]
+}`)
+	writeProjectFile(t, dir, "lenient.bbproj", `{
+    "Name": "Lenient",
+    "Statuses": [
+        {"Provider": "Coveralls", "Url": "`+provider.URL+`/coveralls", "Thresholds": [
+            {"Below": 50, "Color": "orange", "Failing": true},
+            {"Below": 70, "Color": "#abcdef"}
+        ]},
+        {"Provider": "GitHub", "Url": "`+provider.URL+`/releases"}
+    ]
+}`)
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: 8000},
+		Fetch:        badger.FetchConfig{Retries: -1},
+		ProjectsPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+	get := func(path string, code int) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		badgerBadger.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if recorder.Code != code {
+			t.Errorf("Code should be %d and not %d", code, recorder.Code)
+		}
+		return recorder
+	}
+
+	t.Run("ValueBadges", func(t *testing.T) {
+		tests := []struct {
+			path  string
+			value string
+			color string
+		}{
+			{"/sample/build/badge", "passing", "#4c1"},
+			{"/sample/tests/badge", "17 passed, 1 failed", "#e05d44"},
+			{"/sample/coverage/badge", "55.5%", "#e05d44"},
+			{"/sample/release/badge", "v1.2.0", "#007ec6"},
+			{"/lenient/coverage/badge", "55.5%", "#abcdef"},
+		}
+		for _, test := range tests {
+			recorder := get(test.path, http.StatusOK)
+			if recorder.Header().Get("Content-Type") != badger.ValueBadgeContentType {
+				t.Errorf("Content-Type should be '%s' and not '%s'", badger.ValueBadgeContentType, recorder.Header().Get("Content-Type"))
+			}
+			body := recorder.Body.String()
+			if !strings.Contains(body, ">"+test.value+"</text>") || !strings.Contains(body, `fill="`+test.color+`"`) {
+				t.Errorf("Badge %s should show '%s' in '%s':\n%s", test.path, test.value, test.color, body)
+			}
+		}
+	})
+
+	t.Run("Label", func(t *testing.T) {
+		body := get("/sample/coverage/badge?label=code%20coverage", http.StatusOK).Body.String()
+		if !strings.Contains(body, ">code coverage</text>") {
+			t.Errorf("Badge should use the label:\n%s", body)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		get("/lenient/tests/badge", http.StatusNotFound)
+		get("/unknown/coverage/badge", http.StatusNotFound)
+	})
+
+	t.Run("Overall", func(t *testing.T) {
+		body := get("/sample", http.StatusOK).Body.String()
+		for _, expected := range []string{
+			`Overall status: <span class="Failing">Failing</span>`,
+			`AppVeyor tests: <span class="Failing">Failing</span>`,
+			`<span class="value">17 passed, 1 failed</span>`,
+			`Coveralls coverage: <span class="Failing">Failing</span>`,
+			`GitHub release: <span class="Passing">Passing</span>`,
+			`<span class="value">v1.2.0</span>`,
+			`<li>Job 2: <span class="Passing">Passing</span></li>`,
+		} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("Page should contain '%s':\n%s", expected, body)
+			}
+		}
+		// Above the failing threshold, the release doesn't count
+		body = get("/lenient", http.StatusOK).Body.String()
+		if !strings.Contains(body, `Overall status: <span class="Passing">Passing</span>`) {
+			t.Errorf("Lenient thresholds should pass:\n%s", body)
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		validationErrors := badger.ValidateProject(badger.ProjectConfig{
+			Name: "Sample",
+			Statuses: []badger.StatusConfig{
+				{Type: "Coverage", Provider: "TravisCI", URL: "https://api.travis-ci.org"},
+				{Type: "Lines", Provider: "AppVeyor", URL: "https://ci.appveyor.com"},
+				{Provider: "Codecov", URL: "https://codecov.io", Thresholds: []badger.ThresholdConfig{{Below: 50, Color: "purple"}}},
+				{Provider: "GitHub", URL: "https://api.github.com", Thresholds: []badger.ThresholdConfig{{Below: 50, Color: "red"}}},
+				{Type: "Release", Provider: "GitHub", URL: "https://api.github.com"},
+			},
+		})
+		expected := []string{"Statuses[0].Type", "Statuses[1].Type", "Statuses[2].Thresholds[0].Color", "Statuses[3].Thresholds", "Statuses[4]"}
+		if len(validationErrors) != len(expected) {
+			t.Fatalf("Validation errors should be %v, got %v", expected, validationErrors)
+		}
+		for i, field := range expected {
+			if validationErrors[i].Field != field {
+				t.Errorf("Field should be '%s' and not '%s'", field, validationErrors[i].Field)
+			}
+		}
+	})
+}
@@ -13,13 +13,45 @@
 
 package badger
 
-import "./parsers"
+import (
+	"time"
+
+	"./parsers"
+)
 
 const (
 	// ProviderTravisCI is the constant for Travis CI
 	ProviderTravisCI = "travisci"
 	// ProviderAppveyor is the constant for AppVeyor
 	ProviderAppveyor = "appveyor"
+	// ProviderCoveralls is the constant for Coveralls
+	ProviderCoveralls = "coveralls"
+	// ProviderCodecov is the constant for Codecov
+	ProviderCodecov = "codecov"
+	// ProviderGitHub is the constant for GitHub releases
+	ProviderGitHub = "github"
+)
+
+const (
+	// StatusTypeBuild is the build result of a CI provider, the default
+	StatusTypeBuild = "build"
+	// StatusTypeTests are the passed and failed test counts of a build
+	StatusTypeTests = "tests"
+	// StatusTypeCoverage is the covered percentage of a coverage provider
+	StatusTypeCoverage = "coverage"
+	// StatusTypeRelease is the latest release tag, it doesn't change the
+	// overall status of the project
+	StatusTypeRelease = "release"
+)
+
+const (
+	// AuthSchemeBearer sends 'Authorization: Bearer <token>'
+	AuthSchemeBearer = "bearer"
+	// AuthSchemeToken sends 'Authorization: token <token>' as used by
+	// GitHub and Travis CI
+	AuthSchemeToken = "token"
+	// AuthSchemeBasic sends the username and password as basic auth
+	AuthSchemeBasic = "basic"
 )
 
 // BadgeTemplates is the structure for the template JSON
@@ -43,14 +75,38 @@ type OverlayPosition struct {
 
 // BadgeOverlay is the structure for specifying an overlay
 type BadgeOverlay struct {
-	Provider string          `json:"Provider"`
+	Provider string `json:"Provider"`
+	// Position is only used by the absolute layout, it is the top left of
+	// the label or the status badge if there is no label. It is inside the
+	// padding when the badge is auto sized.
 	Position OverlayPosition `json:"Position"`
+	// Label is drawn to the left of the status badge, blank for none
+	Label string `json:"Label"`
 }
 
 // BadgeConfig is the configuration for a specific badge
 type BadgeConfig struct {
 	Template BadgeTemplateConfig `json:"Template"`
 	Overlays []BadgeOverlay      `json:"Overlays"`
+	// Layout places the overlays, one of the BadgeLayoutXXX constants. It
+	// defaults to the absolute positions of the overlays.
+	Layout string `json:"Layout"`
+	// Spacing is the gap in pixels between stacked overlays
+	Spacing int `json:"Spacing"`
+	// Align anchors the stacked overlays horizontally on the canvas, one
+	// of the BadgeAlignXXX constants. They are centered vertically.
+	Align string `json:"Align"`
+	// Padding is the gap in pixels between the overlays and the canvas
+	// edges when they are aligned or the canvas is sized to fit them
+	Padding int `json:"Padding"`
+	// AutoSize sizes the canvas to fit the overlays instead of the
+	// background, which is drawn at the top left when it is set
+	AutoSize bool `json:"AutoSize"`
+	// LabelColor is a colour name or hex colour, it defaults to #333
+	LabelColor string `json:"LabelColor"`
+	// Format is the image format of the badge, one of the BadgeFormatXXX
+	// constants. It defaults to PNG.
+	Format string `json:"Format"`
 }
 
 // PageConfig is the configuration for a badge's page
@@ -58,38 +114,178 @@ type PageConfig struct {
 	Template string `json:"Template"`
 }
 
+// StatusAuthConfig sets up the authentication for a status request
+type StatusAuthConfig struct {
+	// Scheme is one of the AuthSchemeXXX constants, blank for none
+	Scheme   string `json:"Scheme"`
+	Token    string `json:"Token"`
+	Username string `json:"Username"`
+	Password string `json:"Password"`
+}
+
+// ThresholdConfig colours the value badge of a coverage or tests status
+// when the value, the covered or passed percentage, is below Below
+type ThresholdConfig struct {
+	Below float64 `json:"Below"`
+	// Color is a named colour, ie. red, or a hex colour, ie. #e05d44
+	Color string `json:"Color"`
+	// Failing marks the status as failing below the threshold
+	Failing bool `json:"Failing"`
+}
+
 // StatusConfig provides the structure for status configuration
 type StatusConfig struct {
-	Type     string `json:"Type"`
-	Provider string `json:"Provider"`
-	URL      string `json:"Url"`
+	// Type is one of the StatusTypeXXX constants, blank for a build
+	Type     string           `json:"Type"`
+	Provider string           `json:"Provider"`
+	URL      string           `json:"Url"`
+	Auth     StatusAuthConfig `json:"Auth"`
+	// Headers are added to the status request, ie. Travis-API-Version
+	Headers map[string]string `json:"Headers"`
+	// Thresholds override the default thresholds of the status type, the
+	// lowest threshold the value is below applies
+	Thresholds []ThresholdConfig `json:"Thresholds"`
+	// branch is the branch the parser picks the build of, blank for the
+	// latest build, see branchStatuses
+	branch string
+	// untracked is set for branches that are only requested with ?branch=,
+	// their last good results aren't kept
+	untracked bool
 }
 
 // ProjectConfig is the JSON structure for project configurations
 type ProjectConfig struct {
-	Name     string         `json:"Name"`
+	Name string `json:"Name"`
+	// Slug is the project in the URLs, it defaults to the slugified name.
+	// Setting it keeps the URLs stable when the project is renamed.
+	Slug string `json:"Slug"`
+	// Aliases are earlier slugs that redirect to the project
+	Aliases []string `json:"Aliases"`
+	// DefaultBranch is shown when no branch is requested, it replaces the
+	// {branch} placeholder of the status URLs which defaults to master.
+	// Providers that return a single build, ie. AppVeyor, show their
+	// latest build unless the URL has the placeholder.
+	DefaultBranch string `json:"DefaultBranch"`
+	// Branches are tracked, they are refreshed with the project and listed
+	// side by side on the project page
+	Branches []string `json:"Branches"`
+	// Group is the product line of the project, projects of a group are
+	// listed on /groups/{group} and share an aggregate badge
+	Group string `json:"Group"`
+	// Tags are labels the root page can filter on
+	Tags     []string       `json:"Tags"`
 	Statuses []StatusConfig `json:"Statuses"`
 	Badge    BadgeConfig    `json:"Badge"`
-	Page     PageConfig     `json:"Page"`
+	// Badges are named badge variants served on /{project}/badge/{variant},
+	// ie. a banner for the docs, Badge is the default variant
+	Badges map[string]BadgeConfig `json:"Badges"`
+	Page   PageConfig             `json:"Page"`
 }
 
 // PageData is the setup for a project page
 type PageData struct {
+	// BasePath is the base path the page is served on, the templates
+	// build their links with it, ie. {{ url .BasePath "css" "page.css" }}
+	BasePath string
+	// Project is the project slug
+	Project     string
 	ProjectName string
-	Overall     parsers.ProviderResult
-	Providers   map[string]parsers.ProviderResult
+	// Branch is the requested branch, blank for the default statuses
+	Branch    string
+	Overall   parsers.ProviderResult
+	Providers map[string]parsers.ProviderResult
+	// Branches are the statuses of the tracked branches
+	Branches []BranchStatus
+}
+
+// BranchStatus is the cached statuses of a tracked branch of a project
+type BranchStatus struct {
+	Name      string
+	Overall   parsers.ProviderResult
+	Providers map[string]parsers.ProviderResult
+}
+
+// ProjectStatus is a project with its cached statuses on the root page
+type ProjectStatus struct {
+	Slug      string
+	Config    ProjectConfig
+	Overall   parsers.ProviderResult
+	Providers map[string]parsers.ProviderResult
+	// Fetched is when the statuses were fetched, zero before the first
+	// refresh
+	Fetched time.Time
+	// Updated is the latest build time of the providers
+	Updated time.Time
+}
+
+// ProjectGroup is a list of projects under a heading on the root page
+type ProjectGroup struct {
+	// Name is blank when the projects aren't grouped
+	Name string
+	// Slug links the heading to the group page when the projects are
+	// grouped by their project group
+	Slug     string
+	Projects []ProjectStatus
+}
+
+// GroupPageData is the setup for a project group page
+type GroupPageData struct {
+	// BasePath is the base path the page is served on
+	BasePath string
+	// Group is the group slug
+	Group     string
+	GroupName string
+	// Overall aggregates the overall statuses of the projects
+	Overall  parsers.ProviderResult
+	Projects []ProjectStatus
 }
 
 // RootPageData contains the information for the root project list
 type RootPageData struct {
+	// BasePath is the base path the page is served on
+	BasePath string
 	Projects map[string]ProjectConfig
+	// Groups are the filtered and sorted projects with their statuses
+	Groups []ProjectGroup
+	// Tags are all the tags of the projects
+	Tags []string
+	// Filter, Status, Tag, Sort and Group are the query of the page
+	Filter string
+	Status string
+	Tag    string
+	Sort   string
+	Group  string
+	// RefreshSeconds is how often the page refreshes the statuses, 0
+	// disables the refresh
+	RefreshSeconds int
 }
 
 // LogOutputConfig sets up the output formats for log files
 type LogOutputConfig struct {
-	Enabled      bool    `json:"Enabled"`
-	Format       string  `json:"Format"`
+	Enabled bool `json:"Enabled"`
+	// Encoding is one of the LogEncodingXXX constants, text if blank
+	Encoding string `json:"Encoding"`
+	// Format is the go-logging format for the text encoding
+	Format string `json:"Format"`
+	// Path is the log file, 'logs/badger.log' if blank. File only.
+	Path         string  `json:"Path"`
 	RotateSizeMB float64 `json:"RotateSizeMB"`
+	// MaxBackups is the number of rotated files to keep, 3 if zero and
+	// all if negative. File only.
+	MaxBackups int `json:"MaxBackups"`
+	// MaxAgeDays is how long rotated files are kept, 30 if zero and
+	// forever if negative. File only.
+	MaxAgeDays int `json:"MaxAgeDays"`
+	// Compress gzips the rotated files. File only.
+	Compress bool `json:"Compress"`
+	// Network and Address connect to a remote syslog daemon, ie. 'udp'
+	// and 'logs.example.com:514'. Blank uses the local daemon. Syslog only.
+	Network string `json:"Network"`
+	Address string `json:"Address"`
+	// Tag is the syslog tag, 'badger' if blank. Syslog only.
+	Tag string `json:"Tag"`
+	// Facility is 'user', 'daemon' or 'local0' to 'local7'. Syslog only.
+	Facility string `json:"Facility"`
 }
 
 // LogConfig specifies the base setup for loggin
@@ -97,6 +293,39 @@ type LogConfig struct {
 	Level   string          `json:"Level"`
 	Console LogOutputConfig `json:"Console"`
 	File    LogOutputConfig `json:"File"`
+	Syslog  LogOutputConfig `json:"Syslog"`
+	// AccessLog logs every HTTP request to the outputs
+	AccessLog bool `json:"AccessLog"`
+}
+
+// ServerTLSConfig enables HTTPS on the server
+type ServerTLSConfig struct {
+	// CertFile and KeyFile are the PEM files of the server certificate,
+	// they are read again on SIGHUP
+	CertFile string `json:"CertFile"`
+	KeyFile  string `json:"KeyFile"`
+	// MinVersion is '1.0', '1.1', '1.2' or '1.3', 1.2 if blank
+	MinVersion string `json:"MinVersion"`
+	// RedirectPort starts a plain HTTP listener on this port that
+	// redirects to HTTPS, 0 disables it
+	RedirectPort int `json:"RedirectPort"`
+}
+
+// VirtualHostConfig serves a separate set of projects on other host names
+// or base paths
+type VirtualHostConfig struct {
+	// Name identifies the virtual host in reload results and metrics, the
+	// first host name if blank
+	Name string `json:"Name"`
+	// Hosts are the host names served, blank serves every host name
+	Hosts []string `json:"Hosts"`
+	// BasePaths the projects are served on, the root if empty
+	BasePaths []string `json:"BasePaths"`
+	// ProjectsPath is the directory with the project files
+	ProjectsPath string `json:"ProjectsPath"`
+	// PagesPath has page templates and assets that override the default
+	// pages file by file
+	PagesPath string `json:"PagesPath"`
 }
 
 // ServerConfig is the setup for the HTTP server
@@ -104,11 +333,71 @@ type ServerConfig struct {
 	IP       string `json:"IP"`
 	Port     int    `json:"Port"`
 	BasePath string `json:"BasePath"`
+	// BasePaths serves the projects on more base paths besides BasePath
+	BasePaths []string `json:"BasePaths"`
+	// VirtualHosts serve other projects on their own host names or base
+	// paths. Host names that aren't listed serve the default projects.
+	VirtualHosts []VirtualHostConfig `json:"VirtualHosts"`
+	// AdminToken enables the admin endpoints when set
+	AdminToken string `json:"AdminToken"`
+	// TLS serves HTTPS when a certificate is set
+	TLS ServerTLSConfig `json:"TLS"`
+}
+
+// FetchConfig sets up how provider statuses are fetched. Zero values use
+// the defaults.
+type FetchConfig struct {
+	// Retries for failed requests, -1 disables retries
+	Retries int `json:"Retries"`
+	// RetryBackoffMS is the first retry backoff, doubled on every retry
+	RetryBackoffMS int `json:"RetryBackoffMS"`
+	// BreakerThreshold is the number of consecutive failures that opens
+	// the circuit for an endpoint, -1 disables the circuit breaker
+	BreakerThreshold int `json:"BreakerThreshold"`
+	// BreakerCooldownSeconds is how long an open circuit stays open
+	BreakerCooldownSeconds int `json:"BreakerCooldownSeconds"`
+	// TimeoutSeconds is the timeout for a single request
+	TimeoutSeconds int `json:"TimeoutSeconds"`
+	// ProviderTimeoutSeconds overrides TimeoutSeconds per provider
+	ProviderTimeoutSeconds map[string]int `json:"ProviderTimeoutSeconds"`
+	// ProxyURL is the HTTP(S) proxy for all requests. The HTTP_PROXY,
+	// HTTPS_PROXY and NO_PROXY environment variables are used if blank.
+	ProxyURL string `json:"ProxyURL"`
+	// CACertFiles are PEM files with CAs to trust on top of the system CAs
+	CACertFiles []string `json:"CACertFiles"`
+	// ClientCertFile and ClientKeyFile are the PEM files for mutual TLS
+	ClientCertFile string `json:"ClientCertFile"`
+	ClientKeyFile  string `json:"ClientKeyFile"`
+	// MaxIdleConnsPerHost is the number of keep-alive connections per host
+	MaxIdleConnsPerHost int `json:"MaxIdleConnsPerHost"`
+	// IdleConnTimeoutSeconds is how long idle connections are kept
+	IdleConnTimeoutSeconds int `json:"IdleConnTimeoutSeconds"`
+	// RefreshSeconds is how often the statuses of all projects are
+	// refreshed in the background
+	RefreshSeconds int `json:"RefreshSeconds"`
+}
+
+// MetricsConfig sets up the Prometheus metrics endpoint
+type MetricsConfig struct {
+	// Disabled turns the metrics endpoint off
+	Disabled bool `json:"Disabled"`
+	// Path is relative to the base path, '/metrics' if blank
+	Path string `json:"Path"`
 }
 
 // Config is the general configuration for badger
 type Config struct {
-	Log          LogConfig    `json:"Log"`
-	Server       ServerConfig `json:"Server"`
-	ProjectsPath string       `json:"ProjectsPath"`
+	Log          LogConfig     `json:"Log"`
+	Server       ServerConfig  `json:"Server"`
+	Fetch        FetchConfig   `json:"Fetch"`
+	Metrics      MetricsConfig `json:"Metrics"`
+	ProjectsPath string        `json:"ProjectsPath"`
+	// PagesPath has page templates and assets that override the embedded
+	// ones file by file, 'pages' if blank
+	PagesPath string `json:"PagesPath"`
+	// BadgesPath has badge images that override the embedded ones file by
+	// file, 'badges' if blank
+	BadgesPath string `json:"BadgesPath"`
+	// DevMode parses the page templates again when they change
+	DevMode bool `json:"DevMode"`
 }
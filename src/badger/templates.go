@@ -0,0 +1,230 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"./parsers"
+)
+
+const (
+	// layoutsDir has the layouts shared by all the pages
+	layoutsDir = "layouts"
+	// partialsDir has the partials shared by all the pages, ie. status-row
+	partialsDir = "partials"
+)
+
+// templateFuncs are the helper functions available in all the templates
+var templateFuncs = template.FuncMap{
+	"timeago":     timeAgo,
+	"duration":    formatDuration,
+	"shortsha":    shortSHA,
+	"statusClass": statusClass,
+	"url":         joinURL,
+}
+
+// templateSet is the parsed page templates of a pages file system. Every
+// page is parsed with the layouts and partials so that it can use them.
+type templateSet struct {
+	sync.Mutex
+	pages fs.FS
+	// dev reparses the templates when the files change
+	dev bool
+	// signature identifies the files the templates were parsed from
+	signature string
+	// templates and errs are keyed by page path, ie. sample/page.html
+	templates map[string]*template.Template
+	errs      map[string]error
+}
+
+// newTemplateSet parses all the templates in pages
+func newTemplateSet(pages fs.FS, dev bool) *templateSet {
+	set := &templateSet{
+		pages: pages,
+		dev:   dev,
+	}
+	set.parse()
+	return set
+}
+
+// parse parses the layouts and partials, then every page on top of them.
+// A page that fails to parse doesn't affect the other pages.
+func (set *templateSet) parse() {
+	set.templates = make(map[string]*template.Template)
+	set.errs = make(map[string]error)
+	signature, pagePaths, sharedPaths, err := scanTemplates(set.pages)
+	set.signature = signature
+	if err != nil {
+		set.errs[""] = err
+		return
+	}
+
+	base := template.New("").Funcs(templateFuncs)
+	for _, sharedPath := range sharedPaths {
+		err := parseTemplateFile(base, set.pages, sharedPath)
+		if err != nil {
+			set.errs[""] = err
+			return
+		}
+	}
+	for _, pagePath := range pagePaths {
+		page, err := base.Clone()
+		if err == nil {
+			err = parseTemplateFile(page, set.pages, pagePath)
+		}
+		if err != nil {
+			set.errs[pagePath] = err
+			continue
+		}
+		set.templates[pagePath] = page.Lookup(pagePath)
+	}
+}
+
+// scanTemplates lists the page and the shared template files in pages.
+// The signature changes when any of the files change.
+func scanTemplates(pages fs.FS) (string, []string, []string, error) {
+	var signature strings.Builder
+	pagePaths := make([]string, 0)
+	sharedPaths := make([]string, 0)
+	err := fs.WalkDir(pages, ".", func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || path.Ext(filePath) != ".html" {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(&signature, "%s:%d:%d;", filePath, info.Size(), info.ModTime().UnixNano())
+		if strings.HasPrefix(filePath, layoutsDir+"/") || strings.HasPrefix(filePath, partialsDir+"/") {
+			sharedPaths = append(sharedPaths, filePath)
+		} else {
+			pagePaths = append(pagePaths, filePath)
+		}
+		return nil
+	})
+	return signature.String(), pagePaths, sharedPaths, err
+}
+
+// parseTemplateFile parses a file into the set of tmpl, named by its path
+func parseTemplateFile(tmpl *template.Template, pages fs.FS, filePath string) error {
+	content, err := fs.ReadFile(pages, filePath)
+	if err != nil {
+		return err
+	}
+	_, err = tmpl.New(filePath).Parse(string(content))
+	return err
+}
+
+// lookup returns the page template at pagePath. In dev mode the templates
+// are parsed again first if any of the files changed.
+func (set *templateSet) lookup(pagePath string) (*template.Template, error) {
+	set.Lock()
+	defer set.Unlock()
+	if set.dev {
+		signature, _, _, err := scanTemplates(set.pages)
+		if err == nil && signature != set.signature {
+			set.parse()
+		}
+	}
+	if err, ok := set.errs[""]; ok {
+		return nil, err
+	}
+	if err, ok := set.errs[pagePath]; ok {
+		return nil, err
+	}
+	page, ok := set.templates[pagePath]
+	if !ok {
+		return nil, errors.New("Template '" + pagePath + "' does not exist")
+	}
+	return page, nil
+}
+
+// timeAgo describes how long ago t was, ie. '5 minutes ago'
+func timeAgo(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	elapsed := time.Since(t)
+	plural := func(count int, unit string) string {
+		if count == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", count, unit)
+	}
+	switch {
+	case elapsed < time.Minute:
+		return "just now"
+	case elapsed < time.Hour:
+		return plural(int(elapsed/time.Minute), "minute")
+	case elapsed < 24*time.Hour:
+		return plural(int(elapsed/time.Hour), "hour")
+	case elapsed < 30*24*time.Hour:
+		return plural(int(elapsed/(24*time.Hour)), "day")
+	}
+	return t.Format("02 Jan 2006")
+}
+
+// formatDuration rounds a duration to the second, ie. '2m35s'
+func formatDuration(duration time.Duration) string {
+	if duration < time.Second {
+		return duration.Round(time.Millisecond).String()
+	}
+	return duration.Round(time.Second).String()
+}
+
+// shortSHA shortens a commit SHA to 7 characters
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// statusClass returns the CSS class for a provider status
+func statusClass(status string) string {
+	switch status {
+	case parsers.ProviderStatusSuccess, parsers.ProviderStatusFailed:
+		return status
+	}
+	return parsers.ProviderStatusUnknown
+}
+
+// joinURL joins a base path and escaped path segments into a link, ie.
+// {{ url .BasePath .Project "badge" }}
+func joinURL(basePath string, segments ...string) string {
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	return strings.TrimSuffix(basePath, "/") + "/" + strings.Join(escaped, "/")
+}
+
+// templatesFor returns the parsed templates of a site
+func (badger *Badger) templatesFor(site *site) *templateSet {
+	badger.projectsLock.RLock()
+	defer badger.projectsLock.RUnlock()
+	return site.templates
+}
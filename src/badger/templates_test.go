@@ -0,0 +1,126 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	badger "."
+)
+
+func TestTemplates(t *testing.T) {
+	provider := newStatusServer(t, func(r *http.Request) {})
+	defer provider.Close()
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeProjectFile(t, dir, "sample.bbproj", `{
+    "Name": "Sample",
+    "Statuses": [{"Provider": "AppVeyor", "Url": "`+provider.URL+`"}]
+}`)
+	writeProjectFile(t, dir, "custom.bbproj", `{
+    "Name": "Custom",
+    "Statuses": [{"Provider": "AppVeyor", "Url": "`+provider.URL+`"}],
+    "Page": {"Template": "custom/page.html"}
+}`)
+	pagesDir := filepath.Join(dir, "pages")
+	os.MkdirAll(filepath.Join(pagesDir, "custom"), 0755)
+	customPage := writeProjectFile(t, filepath.Join(pagesDir, "custom"), "page.html",
+		`{{ template "layout" . }}{{ define "content" }}Custom {{ shortsha "0123456789abcdef" }} {{ url .BasePath .Project "badge" }} {{ url .BasePath "a b" "c/d" }}{{ end }}`)
+
+	badgerBadger, err := badger.New(badger.Config{
+		Server:       badger.ServerConfig{IP: "127.0.0.1", Port: 8000, BasePath: "/status"},
+		Fetch:        badger.FetchConfig{Retries: -1},
+		ProjectsPath: dir,
+		PagesPath:    pagesDir,
+		DevMode:      true,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+	get := func(path string) string {
+		recorder := httptest.NewRecorder()
+		badgerBadger.Handler().ServeHTTP(recorder, httptest.NewRequest("GET", path, nil))
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Code should be %d and not %d", http.StatusOK, recorder.Code)
+		}
+		return recorder.Body.String()
+	}
+
+	t.Run("Layout", func(t *testing.T) {
+		body := get("/status/sample")
+		for _, expected := range []string{
+			"<title>Sample Status Page</title>",
+			`href="/status/css/page.css"`,
+			`Overall status: <span class="Passing">Passing</span>`,
+			`AppVeyor: <span class="Passing">Passing</span>`,
+		} {
+			if !strings.Contains(body, expected) {
+				t.Errorf("Page should contain '%s':\n%s", expected, body)
+			}
+		}
+	})
+
+	t.Run("Partial", func(t *testing.T) {
+		body := get("/status/sample/status")
+		if !strings.Contains(body, `AppVeyor: <span class="Passing">Passing</span>`) {
+			t.Errorf("Status should contain the status row:\n%s", body)
+		}
+		if strings.Contains(body, "<html") {
+			t.Errorf("Status should not use the layout:\n%s", body)
+		}
+	})
+
+	t.Run("PageTemplate", func(t *testing.T) {
+		body := get("/status/custom")
+		if !strings.Contains(body, "Custom 0123456 /status/custom/badge /status/a%20b/c%2Fd") {
+			t.Errorf("Page should use the project template:\n%s", body)
+		}
+		if !strings.Contains(body, `href="/status/css/page.css"`) {
+			t.Errorf("Page should use the layout:\n%s", body)
+		}
+	})
+
+	t.Run("DevMode", func(t *testing.T) {
+		err := ioutil.WriteFile(customPage, []byte(`{{ template "layout" . }}{{ define "content" }}Changed page{{ end }}`), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body := get("/status/custom")
+		if !strings.Contains(body, "Changed page") {
+			t.Errorf("Page should be parsed again in dev mode:\n%s", body)
+		}
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		for _, pageTemplate := range []string{"../page.html", "/page.html", "page.txt", "partials/status.html"} {
+			validationErrors := badger.ValidateProject(badger.ProjectConfig{
+				Name:     "Custom",
+				Statuses: []badger.StatusConfig{{Provider: "AppVeyor", URL: "https://ci.appveyor.com"}},
+				Page:     badger.PageConfig{Template: pageTemplate},
+			})
+			if len(validationErrors) != 1 || validationErrors[0].Field != "Page.Template" {
+				t.Errorf("Page template '%s' should be invalid, got %v", pageTemplate, validationErrors)
+			}
+		}
+	})
+}
@@ -0,0 +1,133 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// tlsVersions maps the configurable minimum TLS versions
+var tlsVersions = map[string]uint16{
+	"":    tls.VersionTLS12,
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// certificateLoader keeps the server certificate so that it can be
+// replaced while the server is running
+type certificateLoader struct {
+	sync.RWMutex
+	certFile    string
+	keyFile     string
+	certificate *tls.Certificate
+}
+
+// newCertificateLoader loads the certificate and key
+func newCertificateLoader(certFile string, keyFile string) (*certificateLoader, error) {
+	loader := &certificateLoader{
+		certFile: certFile,
+		keyFile:  keyFile,
+	}
+	err := loader.reload()
+	if err != nil {
+		return nil, err
+	}
+	return loader, nil
+}
+
+// reload reads the certificate and key again. The current certificate is
+// kept if they can't be loaded.
+func (loader *certificateLoader) reload() error {
+	certificate, err := tls.LoadX509KeyPair(loader.certFile, loader.keyFile)
+	if err != nil {
+		return errors.New("Unable to load TLS certificate: " + err.Error())
+	}
+	loader.Lock()
+	defer loader.Unlock()
+	loader.certificate = &certificate
+	return nil
+}
+
+// getCertificate implements tls.Config.GetCertificate
+func (loader *certificateLoader) getCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	loader.RLock()
+	defer loader.RUnlock()
+	return loader.certificate, nil
+}
+
+// newServerTLSConfig creates the TLS config for the server, nil when TLS
+// is not enabled
+func newServerTLSConfig(config ServerTLSConfig) (*tls.Config, *certificateLoader, error) {
+	if config.CertFile == "" && config.KeyFile == "" {
+		return nil, nil, nil
+	}
+	minVersion, ok := tlsVersions[config.MinVersion]
+	if !ok {
+		return nil, nil, errors.New("Unknown minimum TLS version '" + config.MinVersion + "'")
+	}
+	loader, err := newCertificateLoader(config.CertFile, config.KeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &tls.Config{
+		MinVersion:     minVersion,
+		GetCertificate: loader.getCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}, loader, nil
+}
+
+// TLSConfig returns the TLS config of the server, nil when TLS is not
+// enabled
+func (badger *Badger) TLSConfig() *tls.Config {
+	return badger.tlsConfig
+}
+
+// ReloadCertificate reads the TLS certificate and key again, the current
+// certificate keeps being served if they can't be loaded
+func (badger *Badger) ReloadCertificate() error {
+	if badger.certificates == nil {
+		return nil
+	}
+	err := badger.certificates.reload()
+	if err != nil {
+		badger.log.Error(err.Error())
+		return err
+	}
+	badger.log.Info("TLS certificate reloaded")
+	return nil
+}
+
+// RedirectHandler redirects plain HTTP requests to the HTTPS server
+func (badger *Badger) RedirectHandler(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		// No port in the host header
+		host = r.Host
+	}
+	if host == "" {
+		host = badger.serverIP
+	}
+	if badger.serverPort != 443 {
+		host = net.JoinHostPort(host, strconv.Itoa(badger.serverPort))
+	}
+	target := "https://" + host + r.URL.RequestURI()
+	http.Redirect(w, r, target, http.StatusMovedPermanently)
+}
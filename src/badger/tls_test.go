@@ -0,0 +1,181 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	badger "."
+)
+
+// writeSelfSignedCert writes a self-signed certificate for 127.0.0.1 with
+// commonName to certPath and keyPath, and returns the certificate PEM
+func writeSelfSignedCert(t *testing.T, certPath string, keyPath string, commonName string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	err = ioutil.WriteFile(certPath, certPEM, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM
+}
+
+func TestServerTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	writeProjectFile(t, dir, "sample.bbproj", validProjectJSON)
+	certPath := filepath.Join(dir, "server.crt")
+	keyPath := filepath.Join(dir, "server.key")
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(writeSelfSignedCert(t, certPath, keyPath, "first"))
+
+	badgerBadger, err := badger.New(badger.Config{
+		Server: badger.ServerConfig{
+			IP:   "127.0.0.1",
+			Port: 8443,
+			TLS:  badger.ServerTLSConfig{CertFile: certPath, KeyFile: keyPath},
+		},
+		ProjectsPath: dir,
+	})
+	if err != nil {
+		t.Fatalf("Unable to create Badger: %s", err.Error())
+	}
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", badgerBadger.TLSConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: http.HandlerFunc(badgerBadger.HealthzHandler)}
+	go server.Serve(listener)
+	defer server.Close()
+	url := "https://" + listener.Addr().String() + "/healthz"
+
+	// serverName connects with a new client and returns the common name
+	// of the server certificate
+	serverName := func(clientConfig *tls.Config) (string, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientConfig}}
+		response, err := client.Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer response.Body.Close()
+		return response.TLS.PeerCertificates[0].Subject.CommonName, nil
+	}
+
+	t.Run("Serves", func(t *testing.T) {
+		name, err := serverName(&tls.Config{RootCAs: roots})
+		if err != nil {
+			t.Fatalf("Unable to connect: %s", err.Error())
+		}
+		if name != "first" {
+			t.Errorf("Certificate should be '%s' and not '%s'", "first", name)
+		}
+	})
+
+	t.Run("MinVersion", func(t *testing.T) {
+		_, err := serverName(&tls.Config{RootCAs: roots, MaxVersion: tls.VersionTLS11})
+		if err == nil {
+			t.Errorf("TLS 1.1 should be refused by default")
+		}
+	})
+
+	t.Run("Reload", func(t *testing.T) {
+		roots.AppendCertsFromPEM(writeSelfSignedCert(t, certPath, keyPath, "second"))
+		err := badgerBadger.ReloadCertificate()
+		if err != nil {
+			t.Fatalf("Unable to reload certificate: %s", err.Error())
+		}
+		name, err := serverName(&tls.Config{RootCAs: roots})
+		if err != nil {
+			t.Fatalf("Unable to connect: %s", err.Error())
+		}
+		if name != "second" {
+			t.Errorf("Certificate should be '%s' and not '%s'", "second", name)
+		}
+	})
+
+	t.Run("ReloadInvalid", func(t *testing.T) {
+		err := ioutil.WriteFile(keyPath, []byte("not a key"), 0600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = badgerBadger.ReloadCertificate()
+		if err == nil {
+			t.Errorf("Reloading an invalid key should fail")
+		}
+		name, err := serverName(&tls.Config{RootCAs: roots})
+		if err != nil {
+			t.Fatalf("Unable to connect: %s", err.Error())
+		}
+		if name != "second" {
+			t.Errorf("The current certificate should be kept, got '%s'", name)
+		}
+	})
+
+	t.Run("Redirect", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		badgerBadger.RedirectHandler(recorder, httptest.NewRequest("GET", "http://badger.example.com:8080/sample/badge?branch=main", nil))
+		expected := "https://badger.example.com:8443/sample/badge?branch=main"
+		if recorder.Code != http.StatusMovedPermanently {
+			t.Errorf("Code should be %d and not %d", http.StatusMovedPermanently, recorder.Code)
+		}
+		if recorder.Header().Get("Location") != expected {
+			t.Errorf("Location should be '%s' and not '%s'", expected, recorder.Header().Get("Location"))
+		}
+	})
+}
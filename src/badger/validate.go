@@ -0,0 +1,518 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"fmt"
+	"image"
+	"io/fs"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ValidationError describes a single problem found in a project file
+type ValidationError struct {
+	// File is the project file the error was found in, if known
+	File string
+	// Line is the 1-based line of the offending value, 0 if unknown
+	Line int
+	// Field is the path to the offending value, ie. Statuses[1].Url
+	Field   string
+	Message string
+}
+
+// Error formats the validation error as file:line: field: message
+func (validationError ValidationError) Error() string {
+	location := validationError.File
+	if validationError.Line > 0 {
+		location = fmt.Sprintf("%s:%d", location, validationError.Line)
+	}
+	message := validationError.Message
+	if validationError.Field != "" {
+		message = validationError.Field + ": " + message
+	}
+	if location == "" {
+		return message
+	}
+	return location + ": " + message
+}
+
+// joinValidationErrors combines validation errors into a single message
+func joinValidationErrors(validationErrors []ValidationError) string {
+	messages := make([]string, len(validationErrors))
+	for i, validationError := range validationErrors {
+		messages[i] = validationError.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// decodeProjectFile strictly decodes a JSON, YAML or TOML project file and
+// validates the result against the project schema. Errors carry the file
+// name and line.
+func decodeProjectFile(fileName string, raw []byte) (ProjectConfig, []ValidationError) {
+	var projectConfig ProjectConfig
+	source, validationError := newConfigSource(fileName, raw)
+	if validationError != nil {
+		return projectConfig, []ValidationError{*validationError}
+	}
+	validationError = source.decode(&projectConfig, true)
+	if validationError != nil {
+		return projectConfig, []ValidationError{*validationError}
+	}
+
+	validationErrors := ValidateProject(projectConfig)
+	locateValidationErrors(source, validationErrors)
+	return projectConfig, validationErrors
+}
+
+// ValidateProject checks a project config against the project schema. It does
+// not touch the file system, see ValidateProjectAssets for that.
+func ValidateProject(projectConfig ProjectConfig) []ValidationError {
+	var validationErrors []ValidationError
+	addError := func(field string, format string, args ...interface{}) {
+		validationErrors = append(validationErrors, ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if strings.TrimSpace(projectConfig.Name) == "" {
+		addError("Name", "a project name is required")
+	} else if projectConfig.Slug == "" {
+		slug := Slugify(projectConfig.Name)
+		if slug == "" {
+			addError("Name", "the name has no letters or digits for a slug, set a Slug")
+		} else if reservedSlugs[slug] {
+			addError("Name", "the slug '%s' is reserved by Badger, set a Slug", slug)
+		}
+	}
+	if projectConfig.Slug != "" {
+		if message := validateSlug(projectConfig.Slug); message != "" {
+			addError("Slug", "slug %s", message)
+		}
+	}
+	aliases := make(map[string]bool)
+	for i, alias := range projectConfig.Aliases {
+		field := fmt.Sprintf("Aliases[%d]", i)
+		if message := validateSlug(alias); message != "" {
+			addError(field, "alias %s", message)
+		} else if alias == projectSlug(projectConfig) {
+			addError(field, "alias can not be the project slug")
+		} else if aliases[alias] {
+			addError(field, "alias '%s' is listed twice", alias)
+		}
+		aliases[alias] = true
+	}
+	if projectConfig.Group != "" {
+		slug := groupSlug(projectConfig.Group)
+		if strings.TrimSpace(projectConfig.Group) == "" {
+			addError("Group", "group can not be blank")
+		} else if slug == "" {
+			addError("Group", "the group has no letters or digits for a slug")
+		} else if reservedSlugs[slug] {
+			addError("Group", "the slug '%s' is reserved by Badger", slug)
+		}
+	}
+	for i, tag := range projectConfig.Tags {
+		if strings.TrimSpace(tag) == "" {
+			addError(fmt.Sprintf("Tags[%d]", i), "tag can not be blank")
+		}
+	}
+	if projectConfig.DefaultBranch != "" && !validBranch(projectConfig.DefaultBranch) {
+		addError("DefaultBranch", "invalid branch name '%s'", projectConfig.DefaultBranch)
+	}
+	branches := make(map[string]bool)
+	for i, branch := range projectConfig.Branches {
+		field := fmt.Sprintf("Branches[%d]", i)
+		if !validBranch(branch) {
+			addError(field, "invalid branch name '%s'", branch)
+		} else if branches[branch] {
+			addError(field, "branch '%s' is listed twice", branch)
+		}
+		branches[branch] = true
+	}
+	if len(projectConfig.Statuses) == 0 {
+		addError("Statuses", "at least one status is required")
+	}
+	statusKeys := make(map[string]bool)
+	for i, status := range projectConfig.Statuses {
+		field := fmt.Sprintf("Statuses[%d]", i)
+		if statusKeys[statusKey(status)] {
+			addError(field, "the '%s' status of '%s' is listed twice", statusType(status), status.Provider)
+		}
+		statusKeys[statusKey(status)] = true
+		if status.Provider == "" {
+			addError(field+".Provider", "a provider is required")
+		} else if _, err := NewParser(status.Provider); err != nil {
+			addError(field+".Provider", "unknown provider '%s'", status.Provider)
+		}
+		if status.URL == "" {
+			addError(field+".Url", "a URL is required")
+		} else {
+			statusURL, err := url.Parse(status.URL)
+			if err != nil {
+				addError(field+".Url", "invalid URL: %s", err.Error())
+			} else if statusURL.Scheme != "http" && statusURL.Scheme != "https" {
+				addError(field+".Url", "URL scheme must be http or https")
+			} else if statusURL.Host == "" {
+				addError(field+".Url", "URL must contain a host")
+			}
+		}
+		switch strings.ToLower(status.Auth.Scheme) {
+		case "":
+		case AuthSchemeBearer, AuthSchemeToken:
+			if status.Auth.Token == "" {
+				addError(field+".Auth.Token", "a token is required for the '%s' scheme", status.Auth.Scheme)
+			}
+		case AuthSchemeBasic:
+			if status.Auth.Username == "" {
+				addError(field+".Auth.Username", "a username is required for the '%s' scheme", status.Auth.Scheme)
+			}
+		default:
+			addError(field+".Auth.Scheme", "unknown auth scheme '%s'", status.Auth.Scheme)
+		}
+		typeName := statusType(status)
+		switch typeName {
+		case StatusTypeBuild, StatusTypeTests, StatusTypeCoverage, StatusTypeRelease:
+			if _, known := statusTypes[strings.ToLower(status.Provider)]; known && !supportsType(status.Provider, typeName) {
+				addError(field+".Type", "provider '%s' does not report '%s' statuses", status.Provider, status.Type)
+			}
+		default:
+			addError(field+".Type", "unknown status type '%s'", status.Type)
+		}
+		if len(status.Thresholds) > 0 && typeName != StatusTypeCoverage && typeName != StatusTypeTests {
+			addError(field+".Thresholds", "thresholds are only supported by coverage and tests statuses")
+		}
+		for j, threshold := range status.Thresholds {
+			if !validColor(threshold.Color) {
+				addError(fmt.Sprintf("%s.Thresholds[%d].Color", field, j), "unknown colour '%s'", threshold.Color)
+			}
+		}
+		for name := range status.Headers {
+			if strings.TrimSpace(name) == "" || strings.ContainsAny(name, " :\r\n") {
+				addError(field+".Headers", "invalid header name '%s'", name)
+			}
+		}
+	}
+
+	if pageTemplate := projectConfig.Page.Template; pageTemplate != "" {
+		if !fs.ValidPath(pageTemplate) || path.Ext(pageTemplate) != ".html" {
+			addError("Page.Template", "page template must be a relative .html path in the pages directory")
+		} else if strings.HasPrefix(pageTemplate, layoutsDir+"/") || strings.HasPrefix(pageTemplate, partialsDir+"/") {
+			addError("Page.Template", "page template can not be a layout or partial")
+		}
+	}
+
+	validationErrors = append(validationErrors, validateBadge("Badge", projectConfig.Badge, projectConfig.Statuses)...)
+	variants := make(map[string]bool)
+	for _, variant := range badgeVariants(projectConfig) {
+		field := "Badges." + variant
+		lowerVariant := strings.ToLower(variant)
+		switch {
+		case !variantPattern.MatchString(lowerVariant):
+			addError(field, "variant names can only contain letters, digits, '-' and '_'")
+		case lowerVariant == defaultVariant:
+			addError(field, "the '%s' variant is the Badge of the project", defaultVariant)
+		case variants[lowerVariant]:
+			addError(field, "variant '%s' is configured twice", lowerVariant)
+		}
+		variants[lowerVariant] = true
+		badgeConfig := projectConfig.Badges[variant]
+		if len(badgeConfig.Overlays) == 0 {
+			addError(field+".Overlays", "a badge variant needs overlays")
+		}
+		validationErrors = append(validationErrors, validateBadge(field, badgeConfig, projectConfig.Statuses)...)
+	}
+	return validationErrors
+}
+
+// validateBadge checks the badge config at field of a project with
+// statuses, ie. Badge or Badges.banner
+func validateBadge(field string, badgeConfig BadgeConfig, statuses []StatusConfig) []ValidationError {
+	var validationErrors []ValidationError
+	addError := func(field string, format string, args ...interface{}) {
+		validationErrors = append(validationErrors, ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if len(badgeConfig.Overlays) > 0 {
+		badges := badgeConfig.Template.Badges
+		if badges.Passing == "" {
+			addError(field+".Template.Badges.Passing", "a passing badge image is required")
+		}
+		if badges.Failing == "" {
+			addError(field+".Template.Badges.Failing", "a failing badge image is required")
+		}
+		if badges.Unknown == "" {
+			addError(field+".Template.Badges.Unknown", "an unknown badge image is required")
+		}
+	}
+	layout := badgeLayout(badgeConfig)
+	if layout != BadgeLayoutAbsolute && layout != BadgeLayoutHorizontal && layout != BadgeLayoutVertical {
+		addError(field+".Layout", "unknown layout '%s'", badgeConfig.Layout)
+	}
+	if align := badgeAlign(badgeConfig); align != BadgeAlignLeft && align != BadgeAlignCenter && align != BadgeAlignRight {
+		addError(field+".Align", "unknown alignment '%s'", badgeConfig.Align)
+	}
+	if badgeConfig.Spacing < 0 {
+		addError(field+".Spacing", "spacing can not be negative")
+	}
+	if badgeConfig.Padding < 0 {
+		addError(field+".Padding", "padding can not be negative")
+	}
+	if badgeConfig.LabelColor != "" && !validColor(badgeConfig.LabelColor) {
+		addError(field+".LabelColor", "unknown colour '%s', use a colour name or #rgb", badgeConfig.LabelColor)
+	}
+	if _, ok := badgeFormats[badgeFormat(badgeConfig)]; !ok {
+		addError(field+".Format", "unknown format '%s', use png, jpeg or gif", badgeConfig.Format)
+	}
+	for i, overlay := range badgeConfig.Overlays {
+		overlayField := fmt.Sprintf("%s.Overlays[%d]", field, i)
+		found := false
+		for _, status := range statuses {
+			if strings.EqualFold(status.Provider, overlay.Provider) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			addError(overlayField+".Provider", "provider '%s' is not listed in Statuses", overlay.Provider)
+		}
+		if overlay.Position.Left < 0 || overlay.Position.Top < 0 {
+			addError(overlayField+".Position", "position can not be negative")
+		}
+		if layout != BadgeLayoutAbsolute && (overlay.Position != OverlayPosition{}) {
+			addError(overlayField+".Position", "position is only used by the absolute layout")
+		}
+		if len(overlay.Label) > maxLabelLength {
+			addError(overlayField+".Label", "label can not be longer than %d characters", maxLabelLength)
+		} else if !validLabel(overlay.Label) {
+			addError(overlayField+".Label", "label can only contain letters, digits, spaces and - _ . : / ( ) + #")
+		}
+	}
+	return validationErrors
+}
+
+// ValidateProjectAssets checks that the badge images referenced by a project
+// exist in badgesPath or the embedded badges and that every overlay fits on
+// the background, for the Badge and every badge variant
+func ValidateProjectAssets(projectConfig ProjectConfig, badgesPath string) []ValidationError {
+	validationErrors := validateBadgeAssets("Badge", projectConfig.Badge, badgesPath)
+	for _, variant := range badgeVariants(projectConfig) {
+		validationErrors = append(validationErrors, validateBadgeAssets("Badges."+variant, projectConfig.Badges[variant], badgesPath)...)
+	}
+	return validationErrors
+}
+
+// validateBadgeAssets checks the badge images of the badge config at field
+func validateBadgeAssets(field string, badgeConfig BadgeConfig, badgesPath string) []ValidationError {
+	var validationErrors []ValidationError
+	if len(badgeConfig.Overlays) == 0 {
+		return validationErrors
+	}
+	addError := func(field string, format string, args ...interface{}) {
+		validationErrors = append(validationErrors, ValidationError{
+			Field:   field,
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	template := badgeConfig.Template
+	badges := badgesFS(badgesPath)
+	var background image.Config
+	if template.Background != "" || !badgeConfig.AutoSize {
+		var err error
+		background, err = decodeImageConfig(badges, badgesPath, template.Background)
+		if err != nil {
+			addError(field+".Template.Background", "%s", err.Error())
+		}
+	}
+
+	// Overlays are checked against the largest status badge
+	var largest image.Config
+	badgeImages := map[string]string{
+		"Passing": template.Badges.Passing,
+		"Failing": template.Badges.Failing,
+		"Unknown": template.Badges.Unknown,
+	}
+	for _, name := range []string{"Passing", "Failing", "Unknown"} {
+		if badgeImages[name] == "" {
+			continue
+		}
+		badgeSize, err := decodeImageConfig(badges, badgesPath, badgeImages[name])
+		if err != nil {
+			addError(field+".Template.Badges."+name, "%s", err.Error())
+			continue
+		}
+		if badgeSize.Width > largest.Width {
+			largest.Width = badgeSize.Width
+		}
+		if badgeSize.Height > largest.Height {
+			largest.Height = badgeSize.Height
+		}
+	}
+
+	if badgeConfig.AutoSize || background.Width == 0 || background.Height == 0 {
+		return validationErrors
+	}
+	overlays := badgeConfig.Overlays
+	sizes := make([]image.Point, len(overlays))
+	for i, overlay := range overlays {
+		sizes[i] = overlaySize(overlay, image.Point{X: largest.Width, Y: largest.Height})
+	}
+	backgroundSize := image.Point{X: background.Width, Y: background.Height}
+	positions, _ := layoutOverlays(badgeConfig, overlays, sizes, backgroundSize)
+	if badgeLayout(badgeConfig) != BadgeLayoutAbsolute {
+		var content image.Rectangle
+		for i := range overlays {
+			content = content.Union(image.Rectangle{Min: positions[i], Max: positions[i].Add(sizes[i])})
+		}
+		if !content.In(image.Rectangle{Max: backgroundSize}) {
+			addError(
+				field+".Layout",
+				"the %dx%d overlays do not fit on the %dx%d background, set AutoSize to fit the canvas to them",
+				content.Dx(),
+				content.Dy(),
+				background.Width,
+				background.Height)
+		}
+		return validationErrors
+	}
+	for i, overlay := range overlays {
+		right := positions[i].X + sizes[i].X
+		bottom := positions[i].Y + sizes[i].Y
+		if right > background.Width || bottom > background.Height {
+			addError(
+				fmt.Sprintf("%s.Overlays[%d].Position", field, i),
+				"a %dx%d badge at %d,%d does not fit on the %dx%d background",
+				sizes[i].X,
+				sizes[i].Y,
+				overlay.Position.Left,
+				overlay.Position.Top,
+				background.Width,
+				background.Height)
+		}
+	}
+	return validationErrors
+}
+
+// ValidateProjectFiles fully validates the given project files, including
+// their badge images and duplicate project slugs across the files
+func ValidateProjectFiles(paths []string, badgesPath string) []ValidationError {
+	var validationErrors []ValidationError
+	// decoded are the files that decoded with their source for locating
+	// the slug conflicts
+	type decodedFile struct {
+		path          string
+		source        *configSource
+		projectConfig ProjectConfig
+	}
+	decoded := make([]decodedFile, 0, len(paths))
+	for _, path := range paths {
+		raw, err := ioutil.ReadFile(path)
+		if err != nil {
+			validationErrors = append(validationErrors, ValidationError{
+				File:    path,
+				Message: err.Error(),
+			})
+			continue
+		}
+		projectConfig, projectErrors := decodeProjectFile(path, raw)
+		validationErrors = append(validationErrors, projectErrors...)
+		if len(projectErrors) > 0 && projectConfig.Name == "" {
+			continue
+		}
+		// The file decoded, so the source can be recreated for locating
+		// the remaining errors
+		source, _ := newConfigSource(path, raw)
+
+		assetErrors := ValidateProjectAssets(projectConfig, badgesPath)
+		locateValidationErrors(source, assetErrors)
+		validationErrors = append(validationErrors, assetErrors...)
+		decoded = append(decoded, decodedFile{path: path, source: source, projectConfig: projectConfig})
+	}
+
+	// The slugs are claimed before the aliases like when loading
+	owners := make(map[string]string)
+	for _, file := range decoded {
+		slug := projectSlug(file.projectConfig)
+		if otherPath, exists := owners[slug]; exists {
+			field := "Name"
+			if file.projectConfig.Slug != "" {
+				field = "Slug"
+			}
+			duplicateError := []ValidationError{{
+				Field:   field,
+				Message: fmt.Sprintf("project slug '%s' is already used by '%s'", slug, otherPath),
+			}}
+			locateValidationErrors(file.source, duplicateError)
+			validationErrors = append(validationErrors, duplicateError...)
+			continue
+		}
+		owners[slug] = file.path
+	}
+	aliasOwners := make(map[string]string)
+	for _, file := range decoded {
+		for i, alias := range file.projectConfig.Aliases {
+			otherPath, exists := owners[alias]
+			if !exists {
+				otherPath, exists = aliasOwners[alias]
+			}
+			if exists && otherPath != file.path {
+				duplicateError := []ValidationError{{
+					Field:   fmt.Sprintf("Aliases[%d]", i),
+					Message: fmt.Sprintf("project alias '%s' is already used by '%s'", alias, otherPath),
+				}}
+				locateValidationErrors(file.source, duplicateError)
+				validationErrors = append(validationErrors, duplicateError...)
+				continue
+			}
+			aliasOwners[alias] = file.path
+		}
+	}
+	return validationErrors
+}
+
+// decodeImageConfig reads the dimensions of the badge image name, badgesPath
+// is only used in the errors
+func decodeImageConfig(badges fs.FS, badgesPath string, name string) (image.Config, error) {
+	path := filepath.Join(badgesPath, name)
+	file, err := badges.Open(name)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("unable to open image '%s'", path)
+	}
+	defer file.Close()
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("unable to decode image '%s': %s", path, err.Error())
+	}
+	return config, nil
+}
+
+// locateValidationErrors sets the file and line for each validation error
+// based on its field path in the source
+func locateValidationErrors(source *configSource, validationErrors []ValidationError) {
+	if source == nil {
+		return
+	}
+	for i := range validationErrors {
+		validationErrors[i].File = source.fileName
+		validationErrors[i].Line = source.line(validationErrors[i].Field)
+	}
+}
@@ -0,0 +1,131 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	badger "."
+)
+
+const validProjectJSON = `{
+    "Name": "Sample",
+    "Statuses": [
+        {
+            "Type": "Build",
+            "Provider": "AppVeyor",
+            "Url": "https://ci.appveyor.com/api/projects/donovansolms/ioRPC"
+        }
+    ],
+    "Badge": {
+        "Template": {
+            "Background": "default.png",
+            "Badges": {
+                "Passing": "build-passing.png",
+                "Failing": "build-failing.png",
+                "Unknown": "build-unknown.png"
+            }
+        },
+        "Overlays": [
+            {
+                "Provider": "AppVeyor",
+                "Position": {
+                    "Left": 130,
+                    "Top": 30
+                }
+            }
+        ]
+    }
+}`
+
+// writeProjectFile writes a project file into dir and returns its path
+func writeProjectFile(t *testing.T, dir string, name string, content string) string {
+	path := filepath.Join(dir, name)
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Unable to write project file: %s", err.Error())
+	}
+	return path
+}
+
+func TestValidateProjectFilesValid(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := writeProjectFile(t, dir, "valid.bbproj", validProjectJSON)
+	validationErrors := badger.ValidateProjectFiles([]string{path}, "")
+	if len(validationErrors) != 0 {
+		t.Errorf("Valid project should not have errors, got %v", validationErrors)
+	}
+}
+
+func TestValidateProjectFilesErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	invalid := strings.Replace(validProjectJSON, `"Provider": "AppVeyor",`, `"Provider": "Jenkins",`, 1)
+	invalid = strings.Replace(invalid, `"Left": 130`, `"Left": 350`, 1)
+	first := writeProjectFile(t, dir, "invalid.bbproj", invalid)
+	second := writeProjectFile(t, dir, "duplicate.bbproj", validProjectJSON)
+	validationErrors := badger.ValidateProjectFiles([]string{first, second}, "")
+
+	expected := []string{
+		first + ":6: Statuses[0].Provider: unknown provider 'Jenkins'",
+		first + ":21: Badge.Overlays[0].Provider: provider 'AppVeyor' is not listed in Statuses",
+		first + ":22: Badge.Overlays[0].Position: a 98x20 badge at 350,30 does not fit on the 400x50 background",
+		second + ":2: Name: project slug 'sample' is already used by '" + first + "'",
+	}
+	if len(validationErrors) != len(expected) {
+		t.Fatalf("Expected %d errors and not %d: %v", len(expected), len(validationErrors), validationErrors)
+	}
+	for i, validationError := range validationErrors {
+		if validationError.Error() != expected[i] {
+			t.Errorf("Error should be '%s' and not '%s'", expected[i], validationError.Error())
+		}
+	}
+}
+
+func TestValidateProjectFilesSyntax(t *testing.T) {
+	dir, err := ioutil.TempDir("", "badger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("InvalidJSON", func(t *testing.T) {
+		path := writeProjectFile(t, dir, "syntax.bbproj", "{\n    \"Name\": \"Sample\",\n    \"Statuses\": [}\n")
+		validationErrors := badger.ValidateProjectFiles([]string{path}, "")
+		if len(validationErrors) != 1 || validationErrors[0].Line != 3 {
+			t.Errorf("Expected a single error on line 3, got %v", validationErrors)
+		}
+	})
+
+	t.Run("UnknownField", func(t *testing.T) {
+		path := writeProjectFile(t, dir, "unknown.bbproj", "{\n    \"Name\": \"Sample\",\n    \"Colour\": \"Red\"\n}\n")
+		validationErrors := badger.ValidateProjectFiles([]string{path}, "")
+		if len(validationErrors) != 1 || validationErrors[0].Line != 3 {
+			t.Errorf("Expected a single error on line 3, got %v", validationErrors)
+		}
+	})
+}
@@ -0,0 +1,145 @@
+/**
+ * This file is part of Badger.
+ * Copyright © 2016 Donovan Solms.
+ * Project Limitless
+ * https://www.projectlimitless.io
+ *
+ * Badger and Project Limitless is free software: you can redistribute it and/or modify
+ * it under the terms of the Apache License Version 2.0.
+ *
+ * You should have received a copy of the Apache License Version 2.0 with
+ * Badger. If not, see http://www.apache.org/licenses/LICENSE-2.0.
+ */
+
+package badger
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"./parsers"
+	"github.com/gorilla/mux"
+)
+
+// ValueBadgeContentType is the content type of the value badges
+const ValueBadgeContentType = "image/svg+xml"
+
+const (
+	// valueBadgeCharWidth is the approximate width of a character of the
+	// 11px badge font
+	valueBadgeCharWidth = 7
+	// valueBadgePadding is the horizontal padding of a badge half
+	valueBadgePadding = 10
+)
+
+// valueBadgeTemplate is a flat badge with a grey label and a coloured value
+const valueBadgeTemplate = `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[2]s: %[3]s">` +
+	`<title>%[2]s: %[3]s</title>` +
+	`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>` +
+	`<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>` +
+	`<g clip-path="url(#r)"><rect width="%[4]d" height="20" fill="#555"/><rect x="%[4]d" width="%[5]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>` +
+	`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">` +
+	`<text x="%[7]s" y="15" fill="#010101" fill-opacity=".3">%[2]s</text><text x="%[7]s" y="14">%[2]s</text>` +
+	`<text x="%[8]s" y="15" fill="#010101" fill-opacity=".3">%[3]s</text><text x="%[8]s" y="14">%[3]s</text>` +
+	`</g></svg>`
+
+// renderValueBadge renders a label and a value as a flat SVG badge
+func renderValueBadge(label string, value string, color string) []byte {
+	labelWidth := len([]rune(label))*valueBadgeCharWidth + valueBadgePadding
+	valueWidth := len([]rune(value))*valueBadgeCharWidth + valueBadgePadding
+	center := func(left int, width int) string {
+		return strconv.FormatFloat(float64(left)+float64(width)/2, 'f', -1, 64)
+	}
+	buffer := new(bytes.Buffer)
+	fmt.Fprintf(buffer, valueBadgeTemplate,
+		labelWidth+valueWidth,
+		html.EscapeString(label),
+		html.EscapeString(value),
+		labelWidth,
+		valueWidth,
+		html.EscapeString(color),
+		center(0, labelWidth),
+		center(labelWidth, valueWidth))
+	return buffer.Bytes()
+}
+
+// typedResult returns the result of the first status of a type of a
+// project. The build statuses are aggregated like the overall status.
+func typedResult(projectConfig ProjectConfig, wanted string, providers map[string]parsers.ProviderResult) (StatusConfig, parsers.ProviderResult, bool) {
+	var found StatusConfig
+	var result parsers.ProviderResult
+	ok := false
+	for _, status := range projectConfig.Statuses {
+		if statusType(status) != wanted {
+			continue
+		}
+		providerResult := providers[statusKey(status)]
+		if !ok {
+			found, result, ok = status, providerResult, true
+			continue
+		}
+		result.Status = aggregateStatus(result.Status, providerResult.Status)
+	}
+	if ok && wanted == StatusTypeBuild {
+		result.Value = strings.ToLower(result.Status)
+	}
+	return found, result, ok
+}
+
+// ValueBadgeHandler handles calls to /{project}/{type}/badge and renders
+// the value of a status type as an SVG badge, ie. /sample/coverage/badge.
+// The label defaults to the type and can be set with ?label=, the branch
+// with ?branch=.
+func (badger *Badger) ValueBadgeHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	project := strings.ToLower(vars["project"])
+	wanted := strings.ToLower(vars["type"])
+	badger.log.Debug("Request received for the %s badge of project '%s'", wanted, project)
+
+	route := badger.routeFor(r)
+	projectConfig, ok := badger.siteProject(route.site, project)
+	if !ok {
+		badger.log.Error("Project config not found for project '%s'", project)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(fmt.Sprintf("Project config not found for project '%s'", project)))
+		return
+	}
+	branch, ok := badger.requestBranch(w, r)
+	if !ok {
+		return
+	}
+	start := time.Now()
+	_, providerStatuses := badger.fetchBranchStatuses(route.site, project, projectConfig, branch)
+	status, result, ok := typedResult(projectConfig, wanted, providerStatuses)
+	if !ok {
+		badger.log.Error("No %s status found for project '%s'", wanted, project)
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(fmt.Sprintf("No %s status found for project '%s'", wanted, project)))
+		return
+	}
+
+	label := r.URL.Query().Get("label")
+	if label == "" {
+		label = wanted
+	}
+	value := result.Value
+	if value == "" {
+		value = strings.ToLower(parsers.ProviderStatusUnknown)
+	}
+	badge := renderValueBadge(label, value, valueColor(status, result))
+	w.Header().Set("Content-Type", ValueBadgeContentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(badge)))
+	w.Header().Set("Cache-Control", "no-cache, private")
+	w.Header().Set("Last-Modified", badger.cacheSince)
+	w.Header().Set("Expires", badger.cacheUntil)
+	_, err := w.Write(badge)
+	if err != nil {
+		badger.log.Error("Unable to write badge to the HTTP output: %s", err.Error())
+	}
+	badger.Metrics.observe(metricBadgeRenderDuration, time.Since(start), route.site.key(project))
+}
@@ -14,51 +14,148 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
 	"flag"
 	"fmt"
-	"io/ioutil"
-	"sync"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"./badger"
 )
 
 func main() {
-	configPath := flag.String("config", "config.json", "Path to the configuration JSON file")
+	configPath := flag.String("config", "config.json", "Path to the configuration JSON, YAML or TOML file")
 	flag.Parse()
-	fmt.Println("Using config file:", *configPath)
 
-	// Check if the config file exists
-	fileBytes, err := ioutil.ReadFile(*configPath)
-	if err != nil {
-		fmt.Println("Unable to read config file:", err.Error())
-		panic(err)
+	if flag.Arg(0) == "validate" {
+		os.Exit(validate(*configPath, flag.Args()[1:]))
 	}
 
+	fmt.Println("Using config file:", *configPath)
+
 	// Parse the config
-	var config badger.Config
-	err = json.Unmarshal(fileBytes, &config)
+	config, err := badger.LoadConfig(*configPath)
 	if err != nil {
-		fmt.Println("Unable to parse config file:", err.Error())
-		panic(err)
+		fmt.Println("Unable to load config file:", err.Error())
+		os.Exit(1)
 	}
 
 	fmt.Println("Setting up Badger...")
 
 	badgerBadger, err := badger.New(config)
 	if err != nil {
-		fmt.Println("Unable to create Badger instance: %s", err.Error())
+		fmt.Println("Unable to create Badger instance:", err.Error())
+		os.Exit(1)
 	}
 
-	var waitGroup sync.WaitGroup
-	waitGroup.Add(1)
+	// Reload the project configs on SIGHUP
+	reloadSignal := make(chan os.Signal, 1)
+	signal.Notify(reloadSignal, syscall.SIGHUP)
 	go func() {
-		defer waitGroup.Done()
-		badgerBadger.Start()
+		for range reloadSignal {
+			fmt.Println("Received SIGHUP, reloading project configs and certificates...")
+			// Failed reloads keep serving the current configs and certificate
+			if _, err := badgerBadger.Reload(); err != nil {
+				fmt.Println("Unable to reload project configs:", err.Error())
+			}
+			if err := badgerBadger.ReloadCertificate(); err != nil {
+				fmt.Println("Unable to reload certificate:", err.Error())
+			}
+		}
 	}()
 
-	fmt.Sprintln("Badger is running on %s:%d", config.Server.IP, config.Server.Port)
-	waitGroup.Wait()
+	// Shut down gracefully on SIGINT and SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	scheme := "http"
+	if badgerBadger.TLSConfig() != nil {
+		scheme = "https"
+	}
+	fmt.Printf("Starting Badger on %s://%s:%d\n", scheme, config.Server.IP, config.Server.Port)
+	err = badgerBadger.Start(ctx)
+	if err != nil {
+		fmt.Println("Badger stopped:", err.Error())
+		os.Exit(1)
+	}
 
 	fmt.Println("Shutdown Badger")
 }
+
+// validate runs the 'validate [files...]' subcommand and returns the exit code.
+// When no files are given, all the project files in the configured
+// projects paths, including those of the virtual hosts, are validated. The
+// projects of each site are validated separately as their slugs are per site.
+func validate(configPath string, args []string) int {
+	validateFlags := flag.NewFlagSet("validate", flag.ExitOnError)
+	badgesPath := validateFlags.String("badges", "badges", "Path to badge images that override the embedded ones, defaults to the configured BadgesPath")
+	validateFlags.Parse(args)
+
+	var config badger.Config
+	configLoaded := false
+	if _, err := os.Stat(configPath); err == nil {
+		config, err = badger.LoadConfig(configPath)
+		if err != nil {
+			fmt.Println("Unable to load config file:", err.Error())
+			return 2
+		}
+		configLoaded = true
+	}
+	badgesFlagSet := false
+	validateFlags.Visit(func(f *flag.Flag) {
+		if f.Name == "badges" {
+			badgesFlagSet = true
+		}
+	})
+	// check the badges the server uses, it falls back to 'badges' as well
+	if !badgesFlagSet && configLoaded && config.BadgesPath != "" {
+		*badgesPath = config.BadgesPath
+	}
+
+	// sites are the project files to validate together
+	sites := [][]string{validateFlags.Args()}
+	if len(sites[0]) == 0 {
+		projectsPaths := []string{"projects"}
+		if config.ProjectsPath != "" {
+			projectsPaths[0] = config.ProjectsPath
+		}
+		for _, virtualHost := range config.Server.VirtualHosts {
+			if virtualHost.ProjectsPath == "" {
+				name := virtualHost.Name
+				if name == "" && len(virtualHost.Hosts) > 0 {
+					name = virtualHost.Hosts[0]
+				}
+				fmt.Printf("Skipping virtual host '%s' without a projects path\n", name)
+				continue
+			}
+			projectsPaths = append(projectsPaths, virtualHost.ProjectsPath)
+		}
+		sites = sites[:0]
+		for _, projectsPath := range projectsPaths {
+			projectFiles, err := badger.ProjectFiles(projectsPath)
+			if err != nil || len(projectFiles) == 0 {
+				fmt.Printf("No project files found in '%s'\n", projectsPath)
+				return 2
+			}
+			sites = append(sites, projectFiles)
+		}
+	}
+
+	problems := 0
+	files := 0
+	for _, siteFiles := range sites {
+		validationErrors := badger.ValidateProjectFiles(siteFiles, *badgesPath)
+		for _, validationError := range validationErrors {
+			fmt.Println(validationError.Error())
+		}
+		problems += len(validationErrors)
+		files += len(siteFiles)
+	}
+	if problems > 0 {
+		fmt.Printf("%d problem(s) found in %d file(s)\n", problems, files)
+		return 1
+	}
+	fmt.Printf("%d file(s) valid\n", files)
+	return 0
+}